@@ -1,8 +1,6 @@
 package main
 
 import (
-	"fmt"
-
 	"github.com/gin-gonic/gin"
 	"github.com/kennethklee/gin-gorm-rest/generator"
 	"gorm.io/gorm"
@@ -16,16 +14,24 @@ var RenderAnimal = animalGenerator.Render()
 var FetchAnimal = animalGenerator.Fetch()
 var CreateAnimal = animalGenerator.Create()
 var UpdateAnimal = animalGenerator.Update(mergeAnimals)
+var PatchAnimal = animalGenerator.Patch()
 var DeleteAnimal = animalGenerator.Delete()
 
 func init() {
+	// Paginate listings, i.e. GET /animals?page=2&per_page=10
+	animalGenerator.Pagination = &generator.Pagination{DefaultSize: 20, MaxSize: 100}
+
+	// Columns clients can pick, i.e. GET /animals?fields=id,name,species
+	animalGenerator.Fields = []string{"id", "name", "species", "age"}
+
 	animals := app.Group("/animals")
 
 	animals.GET("", ListAnimals)
 	// animals.POST("", CreateAnimal, RenderAnimal)
 	// animals.GET("/:animal", FetchAnimal, RenderAnimal)
 	// animals.PUT("/:animal", FetchAnimal, UpdateAnimal, RenderAnimal)
-	// animals.DELETE("/:animal", DeleteAnimal)
+	// animals.PATCH("/:animal", FetchAnimal, PatchAnimal, RenderAnimal)
+	// animals.DELETE("/:animal", FetchAnimal, DeleteAnimal)
 }
 
 // When performing a PUT, we need to merge the input data with the existing data
@@ -40,7 +46,7 @@ func mergeAnimals(src, dest interface{}) error {
 	return nil
 }
 
-// When listing, we need to resolve query params. We can also use this to do pagination or limit results or even set headers
+// When listing, we need to resolve query params. Pagination and field selection are handled by the generator
 func animalResolvers(ctx *gin.Context, queryset *gorm.DB) (ok bool) {
 	ok = true
 
@@ -50,17 +56,5 @@ func animalResolvers(ctx *gin.Context, queryset *gorm.DB) (ok bool) {
 		queryset = queryset.Where("name LIKE ?", startsWith)
 	}
 
-	// Let's limit the results
-	queryset = queryset.Select("id, name, species")
-	queryset = queryset.Limit(20)
-
-	// Even add some headers
-	var count int64
-	if err := queryset.Count(&count).Error; err != nil {
-		ctx.AbortWithStatus(500)
-	} else {
-		ctx.Header("X-Total-Count", fmt.Sprintf("%d", count))
-	}
-
 	return
 }
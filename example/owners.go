@@ -10,7 +10,8 @@ func init() {
 	owners.POST("", ownerHandlers.Create, ownerHandlers.Render)
 	owners.GET("/:owner", ownerHandlers.Fetch, ownerHandlers.Render)
 	owners.PUT("/:owner", ownerHandlers.Fetch, ownerHandlers.Update, ownerHandlers.Render)
-	owners.DELETE("/:owner", ownerHandlers.Delete)
+	owners.PATCH("/:owner", ownerHandlers.Fetch, ownerHandlers.Patch, ownerHandlers.Render)
+	owners.DELETE("/:owner", ownerHandlers.Fetch, ownerHandlers.Delete)
 
 	// Short form of the above would be:
 	// ownerHandlers.Register(app, "/owners")
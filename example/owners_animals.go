@@ -1,16 +1,20 @@
 package main
 
+import "github.com/kennethklee/gin-gorm-rest/generator"
+
 // Manually create associated handlers
 var ListOwnerAnimals = animalGenerator.ListAssociated(OwnerAnimalAssoc, nil)
 var FetchOwnerAnimal = animalGenerator.FetchAssociated(OwnerAnimalAssoc)
 var CreateOwnerAnimal = animalGenerator.CreateAssociated(OwnerAnimalAssoc)
 
 func init() {
-	ownerAnimals := app.Group("/owners/:owner/animals", ownerHandlers.Fetch)
+	// the owner is fetched and the animal is created and appended within one transaction
+	ownerAnimals := app.Group("/owners/:owner/animals", generator.Transaction(DB), ownerHandlers.Fetch)
 
 	ownerAnimals.GET("", ListOwnerAnimals)
 	ownerAnimals.POST("", CreateOwnerAnimal, RenderAnimal)
 	ownerAnimals.GET("/:animal", FetchOwnerAnimal, RenderAnimal)
 	ownerAnimals.PUT("/:animal", FetchOwnerAnimal, UpdateAnimal, RenderAnimal)
-	ownerAnimals.DELETE("/:animal", DeleteAnimal)
+	ownerAnimals.PATCH("/:animal", FetchOwnerAnimal, PatchAnimal, RenderAnimal)
+	ownerAnimals.DELETE("/:animal", FetchOwnerAnimal, DeleteAnimal)
 }
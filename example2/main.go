@@ -19,7 +19,7 @@ type User struct {
 // initialize gin, gorm, and the generator
 var app = gin.Default()
 var db = createDB()
-var userHandlers = generator.New(db, User{}, "user").Handlers(nil, mergeUsers)
+var userHandlers = generator.New(db, User{}, "user").Handlers(nil, nil) // nil merger copies writable fields on update
 
 // in-memory sqlite db
 func createDB() *gorm.DB {
@@ -34,15 +34,6 @@ func createDB() *gorm.DB {
 	return db
 }
 
-// handles record updates
-func mergeUsers(src interface{}, dst interface{}) error {
-	srcUser := src.(*User)
-	dstUser := dst.(*User)
-	dstUser.Name = srcUser.Name
-
-	return nil
-}
-
 func main() {
 	// create the routes with the generated handlers
 	userHandlers.Register(app, "/users")
@@ -0,0 +1,499 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Bulk configures the bulk handlers. Every item is validated first, items failing validation, field rules or the policy
+// are reported by array index, i.e. "[2].name". By default nothing is saved when any item fails.
+type Bulk struct {
+	MaxItems  int // largest number of items per request, defaults to 1000
+	BatchSize int // rows inserted per statement, defaults to 100
+
+	// Saves the valid items and reports the failed ones with 207 Multi-Status instead of saving nothing. Each batch runs
+	// in a savepoint, a failing batch is retried item by item.
+	BestEffort bool
+}
+
+// BulkResponse is the response of a best-effort bulk request where some items failed. Data holds the saved models by
+// array index, null for failed items.
+type BulkResponse struct {
+	Data   interface{}           `json:"data,omitempty"`
+	Errors map[string]string     `json:"errors"`
+	Fields map[string]FieldError `json:"fields,omitempty"`
+}
+
+// A bulk item that passed validation
+type bulkItem struct {
+	index int
+	old   interface{}
+	model interface{}
+	lock  *versionLock
+}
+
+func (g *Generator) bulk() Bulk {
+	bulk := Bulk{}
+	if g.Bulk != nil {
+		bulk = *g.Bulk
+	}
+	if bulk.MaxItems <= 0 {
+		bulk.MaxItems = 1000
+	}
+	if bulk.BatchSize <= 0 {
+		bulk.BatchSize = 100
+	}
+	return bulk
+}
+
+// Decodes a JSON array body. Responds with errors and returns false when it isn't one, is empty or has too many items.
+func (g *Generator) bindItems(c *gin.Context, bulk Bulk, items interface{}) bool {
+	if err := json.NewDecoder(c.Request.Body).Decode(items); err != nil {
+		g.abort(c, g.validationError(c, err))
+		return false
+	}
+	if count := reflect.ValueOf(items).Elem().Len(); count == 0 {
+		g.abort(c, invalid(map[string]string{"items": "required"}))
+		return false
+	} else if count > bulk.MaxItems {
+		g.abort(c, invalid(map[string]string{"items": fmt.Sprintf("max %d", bulk.MaxItems)}))
+		return false
+	}
+	return true
+}
+
+// Adds the field errors of an item keyed by its array index
+func indexErrors(errs *Error, index int, item *Error) {
+	for key, msg := range item.Errors {
+		errs.Errors[fmt.Sprintf("[%d].%s", index, key)] = msg
+	}
+	for key, detail := range item.Fields {
+		if errs.Fields == nil {
+			errs.Fields = make(map[string]FieldError)
+		}
+		errs.Fields[fmt.Sprintf("[%d].%s", index, key)] = detail
+	}
+}
+
+// Adds an error of a whole item keyed by its array index
+func indexError(errs *Error, index int, msg string) {
+	errs.Errors[fmt.Sprintf("[%d]", index)] = msg
+}
+
+// Bulk requests can't carry the ETag of each record, so they can't be conditional. Responds with 428 Precondition
+// Required when the generator requires preconditions, or 412 Precondition Failed when If-Match is sent, and returns
+// false.
+func (g *Generator) bulkPreconditions(c *gin.Context) bool {
+	if g.RequirePreconditions {
+		g.abort(c, newError(http.StatusPreconditionRequired, "bulk requests can't be conditional"))
+		return false
+	} else if c.GetHeader("If-Match") != "" {
+		g.abort(c, newError(http.StatusPreconditionFailed, "bulk requests can't be conditional"))
+		return false
+	}
+	return true
+}
+
+// Error of a record item the policy denies, see HideForbidden
+func (g *Generator) forbiddenItem() string {
+	if g.HideForbidden {
+		return "not found"
+	}
+	return "forbidden"
+}
+
+// Adds the error an item failed to save with
+func (g *Generator) saveError(errs *Error, index int, err error) {
+	if e := g.constraintError(err); e != nil {
+		err = e
+	}
+	var e *Error
+	if errors.As(err, &e) && len(e.Errors) > 0 {
+		indexErrors(errs, index, e)
+	} else if errors.As(err, &e) {
+		indexError(errs, index, e.Message)
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		indexError(errs, index, "not found")
+	} else {
+		indexError(errs, index, err.Error())
+	}
+}
+
+// Responds with the validation errors of the items and returns false, unless the items are saved on a best-effort basis
+// and some are left.
+func (g *Generator) abortItemErrors(c *gin.Context, bulk Bulk, errs *Error, items []bulkItem) bool {
+	if len(errs.Errors) == 0 || (bulk.BestEffort && len(items) > 0) {
+		return true
+	}
+	g.abort(c, errs)
+	return false
+}
+
+// Saves the items in a transaction, then renders the saved models by array index. Best-effort saves run each batch in a
+// savepoint and retry the items of a failing batch one by one.
+func (g *Generator) saveItems(c *gin.Context, bulk Bulk, status int, total int, items []bulkItem, errs *Error, save func(tx *gorm.DB, items []bulkItem) error) {
+	fields, fieldErrs, err := g.fieldset(c)
+	if err != nil {
+		g.abort(c, err)
+		return
+	} else if fieldErrs != nil {
+		g.abort(c, invalid(fieldErrs))
+		return
+	}
+
+	saved := make([]interface{}, total)
+	err = g.db(c).Transaction(func(tx *gorm.DB) error {
+		if !bulk.BestEffort {
+			return save(tx, items)
+		}
+		for start := 0; start < len(items); start += bulk.BatchSize {
+			end := start + bulk.BatchSize
+			if end > len(items) {
+				end = len(items)
+			}
+			if err := tx.Transaction(func(tx *gorm.DB) error { return save(tx, items[start:end]) }); err == nil {
+				continue
+			}
+			for i := start; i < end; i++ {
+				if err := tx.Transaction(func(tx *gorm.DB) error { return save(tx, items[i:i+1]) }); err != nil {
+					g.saveError(errs, items[i].index, err)
+					items[i].model = nil
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		g.abort(c, err)
+		return
+	}
+
+	for _, item := range items {
+		if item.model != nil {
+			saved[item.index] = item.model
+		}
+	}
+	data, err := fields.project(saved)
+	if err != nil {
+		g.abort(c, err)
+	} else if len(errs.Errors) > 0 {
+		c.JSON(http.StatusMultiStatus, BulkResponse{data, errs.Errors, errs.Fields})
+	} else {
+		c.JSON(status, data)
+	}
+}
+
+// Creates a handler that creates the models of a JSON array in batches with CreateInBatches. Responds with 201 Created
+// and the created models.
+func (g *Generator) BulkCreate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bulk := g.bulk()
+		var raws []json.RawMessage
+		if !g.bindItems(c, bulk, &raws) {
+			return
+		}
+		scope, ok := g.scope(c)
+		if !ok {
+			return
+		}
+
+		errs := invalid(make(map[string]string))
+		var items []bulkItem
+		for i, raw := range raws {
+			inst := g.new()
+			if err := json.Unmarshal(raw, inst); err != nil {
+				indexErrors(errs, i, g.validationError(c, err))
+				continue
+			} else if err := validateStruct(inst); err != nil {
+				indexErrors(errs, i, g.validationError(c, err))
+				continue
+			}
+			if itemErrs, err := g.guardWrites(c, inst, nil, fieldNonZero(inst)); err != nil {
+				g.abort(c, err)
+				return
+			} else if itemErrs != nil {
+				indexErrors(errs, i, invalid(itemErrs))
+				continue
+			}
+			if err := scope.stamp(inst); err != nil {
+				g.abort(c, err)
+				return
+			}
+			if g.Policy != nil && !g.Policy.CanCreate(c, inst) {
+				indexError(errs, i, "forbidden")
+				continue
+			}
+			items = append(items, bulkItem{index: i, model: inst})
+		}
+		if !g.abortItemErrors(c, bulk, errs, items) {
+			return
+		}
+
+		g.saveItems(c, bulk, http.StatusCreated, len(raws), items, errs, func(tx *gorm.DB, items []bulkItem) error {
+			models := reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(g.model)), 0, len(items))
+			for _, item := range items {
+				if err := g.BeforeCreate.run(c, tx, item.model); err != nil {
+					return err
+				}
+				models = reflect.Append(models, reflect.ValueOf(item.model))
+			}
+			if err := tx.CreateInBatches(models.Interface(), bulk.BatchSize).Error; err != nil {
+				return err
+			}
+			for _, item := range items {
+				if err := g.AfterCreate.run(c, tx, item.model); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// Creates a handler that partially updates the records of a JSON array of objects holding the primary key and the
+// changed keys, i.e. [{"id": 1, "name": "Rex"}]. Keys are applied like Patch does, and only changed columns are saved.
+// Records changed by another request since they were read fail with ErrPreconditionFailed when the generator has a
+// VersionField. Responds with the updated models.
+func (g *Generator) BulkPatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !g.bulkPreconditions(c) {
+			return
+		}
+		bulk := g.bulk()
+		var bodies []map[string]json.RawMessage
+		if !g.bindItems(c, bulk, &bodies) {
+			return
+		}
+		fields, err := g.jsonFields()
+		if err != nil {
+			g.abort(c, err)
+			return
+		}
+		pk, err := g.primaryField()
+		if err != nil {
+			g.abort(c, err)
+			return
+		}
+		scope, ok := g.scope(c)
+		if !ok {
+			return
+		}
+
+		errs := invalid(make(map[string]string))
+		keys := make([]string, len(bodies))
+		var ids []interface{}
+		for i, body := range bodies {
+			raw, exists := body[jsonName(pk)]
+			if !exists {
+				indexErrors(errs, i, invalid(map[string]string{jsonName(pk): "required"}))
+				continue
+			}
+			id := reflect.New(pk.FieldType)
+			if err := json.Unmarshal(raw, id.Interface()); err != nil {
+				indexErrors(errs, i, invalid(map[string]string{jsonName(pk): "invalid " + pk.FieldType.String() + " type"}))
+				continue
+			}
+			keys[i] = fmt.Sprint(id.Elem().Interface())
+			ids = append(ids, id.Elem().Interface())
+			delete(body, jsonName(pk))
+		}
+		records, err := g.findItems(c, scope, pk, ids, false)
+		if err != nil {
+			g.abort(c, err)
+			return
+		}
+
+		var items []bulkItem
+		seen := make(map[string]bool)
+		for i, body := range bodies {
+			if keys[i] == "" {
+				continue
+			} else if seen[keys[i]] {
+				indexError(errs, i, "duplicate")
+				continue
+			}
+			seen[keys[i]] = true
+			dest, exists := records[keys[i]]
+			if !exists {
+				indexError(errs, i, "not found")
+				continue
+			}
+
+			inst := g.copy(dest)
+			if itemErr := g.applyKeys(c, fields, inst, body); itemErr != nil {
+				indexErrors(errs, i, itemErr)
+				continue
+			}
+			if itemErrs, err := g.guardWrites(c, inst, dest, fieldChanged(inst, dest)); err != nil {
+				g.abort(c, err)
+				return
+			} else if itemErrs != nil {
+				indexErrors(errs, i, invalid(itemErrs))
+				continue
+			}
+			lock, err := g.versionLock(dest)
+			if err == nil {
+				err = scope.stamp(inst)
+			}
+			if err != nil {
+				g.abort(c, err)
+				return
+			}
+			if g.Policy != nil && !g.Policy.CanUpdate(c, dest, inst) {
+				indexError(errs, i, g.forbiddenItem())
+				continue
+			}
+			items = append(items, bulkItem{index: i, old: dest, model: inst, lock: lock})
+		}
+		if !g.abortItemErrors(c, bulk, errs, items) {
+			return
+		}
+
+		g.saveItems(c, bulk, http.StatusOK, len(bodies), items, errs, func(tx *gorm.DB, items []bulkItem) error {
+			for _, item := range items {
+				if err := g.saveChanges(c, tx, scope, item.lock, item.old, item.model); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// Creates a handler that deletes the records listed by primary key with ?ids=1,2,3. Soft deleted models are purged with
+// ?force=true like Delete does, and versioned records are deleted at the version they were read at. Responds with 204
+// No Content.
+func (g *Generator) BulkDelete() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !g.bulkPreconditions(c) {
+			return
+		}
+		bulk := g.bulk()
+		param := c.Query("ids")
+		if param == "" {
+			g.abort(c, invalid(map[string]string{"ids": "required"}))
+			return
+		}
+		values := strings.Split(param, ",")
+		if len(values) > bulk.MaxItems {
+			g.abort(c, invalid(map[string]string{"ids": fmt.Sprintf("max %d", bulk.MaxItems)}))
+			return
+		}
+		force := forceRequested(c)
+		if force && !g.purging(c) {
+			g.abortForbidden(c, false)
+			return
+		}
+		pk, err := g.primaryField()
+		if err != nil {
+			g.abort(c, err)
+			return
+		}
+		scope, ok := g.scope(c)
+		if !ok {
+			return
+		}
+
+		errs := invalid(make(map[string]string))
+		keys := make([]string, len(values))
+		var ids []interface{}
+		for i, value := range values {
+			id, err := parseFieldValue(pk, strings.TrimSpace(value))
+			if err != nil {
+				indexError(errs, i, "invalid "+pk.FieldType.String()+" type")
+				continue
+			}
+			keys[i] = fmt.Sprint(id)
+			ids = append(ids, id)
+		}
+		records, err := g.findItems(c, scope, pk, ids, force)
+		if err != nil {
+			g.abort(c, err)
+			return
+		}
+
+		var items []bulkItem
+		seen := make(map[string]bool)
+		for i, key := range keys {
+			if key == "" {
+				continue
+			} else if seen[key] {
+				indexError(errs, i, "duplicate")
+				continue
+			}
+			seen[key] = true
+			model, exists := records[key]
+			if !exists {
+				indexError(errs, i, "not found")
+				continue
+			}
+			if g.Policy != nil && !g.Policy.CanDelete(c, model) {
+				indexError(errs, i, g.forbiddenItem())
+				continue
+			}
+			lock, err := g.versionLock(model)
+			if err != nil {
+				g.abort(c, err)
+				return
+			}
+			items = append(items, bulkItem{index: i, model: model, lock: lock})
+		}
+		if !g.abortItemErrors(c, bulk, errs, items) {
+			return
+		}
+
+		err = g.db(c).Transaction(func(tx *gorm.DB) error {
+			for _, item := range items {
+				err := tx.Transaction(func(tx *gorm.DB) error { return g.deleteModel(c, tx, scope, item.lock, force, item.model) })
+				if err != nil && !bulk.BestEffort {
+					return err
+				} else if err != nil {
+					g.saveError(errs, item.index, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			g.abort(c, err)
+		} else if len(errs.Errors) > 0 {
+			c.JSON(http.StatusMultiStatus, BulkResponse{Errors: errs.Errors, Fields: errs.Fields})
+		} else {
+			c.Status(http.StatusNoContent)
+		}
+	}
+}
+
+// Retrieves the records of the scope with the given primary keys, keyed by their formatted primary key. Soft deleted
+// records are included when purging.
+func (g *Generator) findItems(c *gin.Context, scope scopeValues, pk *schema.Field, ids []interface{}, purging bool) (map[string]interface{}, error) {
+	records := make(map[string]interface{})
+	if len(ids) == 0 {
+		return records, nil
+	}
+
+	found := g.newSlice()
+	queryset := scope.where(g.db(c).Model(found))
+	if purging {
+		queryset = queryset.Unscoped()
+	}
+	in := clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Values: ids}
+	if err := queryset.Where(in).Find(found).Error; err != nil {
+		return nil, err
+	}
+
+	slice := reflect.ValueOf(found).Elem()
+	for i := 0; i < slice.Len(); i++ {
+		model := slice.Index(i).Addr().Interface()
+		records[fmt.Sprint(fieldValue(pk, reflect.ValueOf(model)))] = model
+	}
+	return records, nil
+}
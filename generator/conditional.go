@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/schema"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Sets the ETag and Last-Modified headers of a representation, then checks them against the If-None-Match and
+// If-Modified-Since headers of GET and HEAD requests. Returns true when the client already has the representation.
+func (g *Generator) notModified(c *gin.Context, etag string, model interface{}) (bool, error) {
+	modified, err := g.lastModified(model)
+	if err != nil {
+		return false, err
+	}
+
+	c.Header("ETag", etag)
+	if !modified.IsZero() {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead || c.Writer.Status() != http.StatusOK {
+		return false, nil
+	}
+
+	// If-Modified-Since is only considered without If-None-Match, RFC 7232 section 6
+	if header := c.GetHeader("If-None-Match"); header != "" {
+		return etagMatch(header, etag, true), nil
+	}
+	if header := c.GetHeader("If-Modified-Since"); header != "" && !modified.IsZero() {
+		since, err := http.ParseTime(header)
+		return err == nil && !modified.Truncate(time.Second).After(since), nil
+	}
+	return false, nil
+}
+
+// Computes the weak ETag of a listing from its JSON representation and the headers describing the page.
+func listETag(raw []byte, header http.Header) string {
+	hash := sha256.New()
+	hash.Write(raw)
+	for _, name := range []string{"X-Total-Count", "Link"} {
+		hash.Write([]byte(name + ":" + header.Get(name) + "\n"))
+	}
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// Responds with a listing, or 304 Not Modified when the client already has it.
+func (g *Generator) respondList(c *gin.Context, body interface{}, results interface{}) {
+	raw, err := json.Marshal(body)
+	if err == nil {
+		var notModified bool
+		if notModified, err = g.notModified(c, listETag(raw, c.Writer.Header()), results); err == nil && notModified {
+			c.AbortWithStatus(http.StatusNotModified)
+			return
+		}
+	}
+	if err != nil {
+		g.abort(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", raw)
+}
+
+// Finds the latest modification time of a model or a slice of models. Zero when there is no timestamp field.
+func (g *Generator) lastModified(models interface{}) (time.Time, error) {
+	var latest time.Time
+	field, err := g.lastModifiedField()
+	if err != nil || field == nil {
+		return latest, err
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(models))
+	items := []reflect.Value{value}
+	if value.Kind() == reflect.Slice {
+		items = items[:0]
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, reflect.Indirect(value.Index(i)))
+		}
+	}
+
+	for _, item := range items {
+		if !item.IsValid() || item.Type() != g.model {
+			continue
+		}
+		switch t := fieldValue(field, item).(type) {
+		case time.Time:
+			if t.After(latest) {
+				latest = t
+			}
+		case *time.Time:
+			if t != nil && t.After(latest) {
+				latest = *t
+			}
+		}
+	}
+	return latest, nil
+}
+
+// Looks up the timestamp field of the model, nil when there is none.
+func (g *Generator) lastModifiedField() (*schema.Field, error) {
+	if g.LastModifiedField == "" {
+		s, err := g.schema()
+		if err != nil {
+			return nil, err
+		}
+		if field := s.LookUpField("UpdatedAt"); field != nil && indirectType(field.FieldType) == timeType {
+			return field, nil
+		}
+		return nil, nil
+	}
+
+	fields, err := g.jsonFields()
+	if err != nil {
+		return nil, err
+	}
+	field, exists := fields[g.LastModifiedField]
+	if !exists {
+		return nil, fmt.Errorf("unknown last modified field %s", g.LastModifiedField)
+	} else if indirectType(field.FieldType) != timeType {
+		return nil, fmt.Errorf("last modified field %s must be a time", g.LastModifiedField)
+	}
+	return field, nil
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
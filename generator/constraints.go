@@ -0,0 +1,223 @@
+package generator
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Kinds of database constraint violations
+const (
+	violationUnique = iota + 1
+	violationForeignKey
+	violationNotNull
+	violationCheck
+)
+
+// A database constraint violation and the columns it names, when the driver reports them
+type violation struct {
+	kind    int
+	columns []string
+	index   string // MySQL reports the name of the violated unique index rather than its columns
+}
+
+var (
+	postgresKeyRegex   = regexp.MustCompile(`Key \(([^)]+)\)=`)
+	mysqlDuplicateKey  = regexp.MustCompile(`for key '(?:[^'.]+\.)?([^']+)'`)
+	mysqlForeignKey    = regexp.MustCompile("FOREIGN KEY \\(`([^`]+)`\\)")
+	mysqlColumnRegex   = regexp.MustCompile(`(?:Column|Field) '([^']+)'`)
+	sqliteColumnsRegex = regexp.MustCompile(`(?:UNIQUE|NOT NULL) constraint failed: (.+)$`)
+)
+
+// Translates a constraint violation into an *Error naming the violated fields by json name, nil for other errors.
+// Unique violations respond with 409 Conflict, foreign key and check violations with 422 Unprocessable Entity and
+// not-null violations with field validation errors. The raw driver message is never sent, so schema details don't
+// leak.
+func (g *Generator) constraintError(err error) *Error {
+	v := detectViolation(err)
+	if v == nil {
+		return nil
+	}
+
+	fields := g.violatedFields(v)
+	switch v.kind {
+	case violationUnique:
+		return &Error{Status: http.StatusConflict, Code: "unique_violation", Message: "already exists", Errors: fieldErrors(fields, "already exists")}
+	case violationForeignKey:
+		return &Error{Status: http.StatusUnprocessableEntity, Code: "foreign_key_violation", Message: "invalid reference", Errors: fieldErrors(fields, "invalid reference")}
+	case violationNotNull:
+		return &Error{Status: http.StatusBadRequest, Code: CodeValidationFailed, Message: "validation errors", Errors: fieldErrors(fields, "required")}
+	default:
+		return &Error{Status: http.StatusUnprocessableEntity, Code: "check_violation", Message: "constraint violated"}
+	}
+}
+
+// Maps the columns of a violation to json names, leaving out columns that aren't serialized
+func (g *Generator) violatedFields(v *violation) []string {
+	s, err := g.schema()
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	if v.index == "PRIMARY" {
+		for _, field := range s.PrimaryFields {
+			names = append(names, jsonName(field))
+		}
+	} else if v.index != "" {
+		// guess the column from the index name, i.e. idx_animals_name, preferring the longest column name
+		var match string
+		for _, field := range s.Fields {
+			if field.DBName != "" && strings.HasSuffix(v.index, field.DBName) && len(field.DBName) > len(match) {
+				match = field.DBName
+			}
+		}
+		v.columns = append(v.columns, match)
+	}
+	for _, column := range v.columns {
+		if field := s.LookUpField(column); field != nil && field.DBName == column {
+			names = append(names, jsonName(field))
+		}
+	}
+
+	var fields []string
+	for _, name := range names {
+		if name != "" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+func fieldErrors(fields []string, msg string) map[string]string {
+	if len(fields) == 0 {
+		return nil
+	}
+	errs := make(map[string]string, len(fields))
+	for _, field := range fields {
+		errs[field] = msg
+	}
+	return errs
+}
+
+// Recognizes the constraint violations of the Postgres (pgx and lib/pq), MySQL and SQLite drivers, and the errors gorm
+// translates them into with TranslateError. Drivers are matched by their error types so none has to be imported.
+func detectViolation(err error) *violation {
+	var postgres interface {
+		error
+		SQLState() string
+	}
+	if errors.As(err, &postgres) {
+		return postgresViolation(postgres.SQLState(), postgres)
+	}
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		value := reflect.Indirect(reflect.ValueOf(e))
+		if value.Kind() != reflect.Struct {
+			continue
+		}
+		if value.Type().Name() == "MySQLError" {
+			if number := value.FieldByName("Number"); number.IsValid() && number.CanUint() {
+				return mysqlViolation(number.Uint(), e.Error())
+			}
+		}
+		if strings.Contains(value.Type().PkgPath(), "sqlite") {
+			return sqliteViolation(e.Error())
+		}
+	}
+
+	// gorm.ErrDuplicatedKey and gorm.ErrForeignKeyViolated, which older gorm versions don't declare
+	switch err.Error() {
+	case "duplicated key not allowed":
+		return &violation{kind: violationUnique}
+	case "violates foreign key constraint":
+		return &violation{kind: violationForeignKey}
+	}
+	return nil
+}
+
+func postgresViolation(state string, err error) *violation {
+	value := reflect.Indirect(reflect.ValueOf(err))
+	field := func(names ...string) string {
+		for _, name := range names {
+			if f := value.FieldByName(name); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+				return f.String()
+			}
+		}
+		return ""
+	}
+	keyColumns := func() []string {
+		if matches := postgresKeyRegex.FindStringSubmatch(field("Detail")); matches != nil {
+			return splitColumns(matches[1])
+		}
+		return nil
+	}
+
+	switch state {
+	case "23505":
+		return &violation{kind: violationUnique, columns: keyColumns()}
+	case "23503":
+		return &violation{kind: violationForeignKey, columns: keyColumns()}
+	case "23502":
+		return &violation{kind: violationNotNull, columns: splitColumns(field("ColumnName", "Column"))}
+	case "23514":
+		return &violation{kind: violationCheck}
+	}
+	return nil
+}
+
+func mysqlViolation(number uint64, msg string) *violation {
+	switch number {
+	case 1062:
+		if matches := mysqlDuplicateKey.FindStringSubmatch(msg); matches != nil {
+			return &violation{kind: violationUnique, index: matches[1]}
+		}
+		return &violation{kind: violationUnique}
+	case 1451, 1452:
+		if matches := mysqlForeignKey.FindStringSubmatch(msg); matches != nil {
+			return &violation{kind: violationForeignKey, columns: []string{matches[1]}}
+		}
+		return &violation{kind: violationForeignKey}
+	case 1048, 1364:
+		if matches := mysqlColumnRegex.FindStringSubmatch(msg); matches != nil {
+			return &violation{kind: violationNotNull, columns: []string{matches[1]}}
+		}
+		return &violation{kind: violationNotNull}
+	case 3819:
+		return &violation{kind: violationCheck}
+	}
+	return nil
+}
+
+func sqliteViolation(msg string) *violation {
+	var columns []string
+	if matches := sqliteColumnsRegex.FindStringSubmatch(msg); matches != nil {
+		for _, column := range splitColumns(matches[1]) {
+			columns = append(columns, column[strings.LastIndex(column, ".")+1:]) // strip the table
+		}
+	}
+
+	switch {
+	case strings.Contains(msg, "UNIQUE constraint failed"):
+		return &violation{kind: violationUnique, columns: columns}
+	case strings.Contains(msg, "FOREIGN KEY constraint failed"):
+		return &violation{kind: violationForeignKey}
+	case strings.Contains(msg, "NOT NULL constraint failed"):
+		return &violation{kind: violationNotNull, columns: columns}
+	case strings.Contains(msg, "CHECK constraint failed"):
+		return &violation{kind: violationCheck}
+	}
+	return nil
+}
+
+func splitColumns(list string) []string {
+	var columns []string
+	for _, column := range strings.Split(list, ",") {
+		if column = strings.Trim(strings.TrimSpace(column), `"`); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,248 @@
+package generator
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// CursorPageResponse is the envelope of a cursor paginated listing when Pagination.Envelope is enabled.
+type CursorPageResponse struct {
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	PrevCursor string      `json:"prev_cursor,omitempty"`
+	Limit      int         `json:"limit"`
+}
+
+// Used to sign cursors when Pagination.CursorSecret is not set
+var defaultCursorSecret = randomSecret()
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// Position of a cursor within the ordered keys
+type cursor struct {
+	Values []string `json:"v"`
+	Prev   bool     `json:"p,omitempty"` // page backwards from the position
+}
+
+// A requested cursor page of results
+type cursorPage struct {
+	keys    []*schema.Field
+	secret  []byte
+	from    *cursor
+	limit   int
+	request *http.Request
+
+	next string
+	prev string
+}
+
+// Parses the cursor and limits the queryset to the page after (or before) it. The queryset is ordered by the cursor keys.
+func (g *Generator) paginateCursor(c *gin.Context, queryset *gorm.DB, limit int) (*cursorPage, error) {
+	keys, err := g.cursorKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &cursorPage{keys: keys, secret: g.Pagination.CursorSecret, limit: limit, request: c.Request}
+	if len(p.secret) == 0 {
+		p.secret = defaultCursorSecret
+	}
+
+	if token := c.Query("cursor"); token != "" {
+		if p.from, err = p.decode(token); err != nil {
+			return nil, err
+		}
+		where, err := p.after(p.from)
+		if err != nil {
+			return nil, err
+		}
+		queryset.Where(where)
+	}
+
+	desc := p.from != nil && p.from.Prev
+	for _, key := range keys {
+		queryset.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: key.DBName}, Desc: desc})
+	}
+	queryset.Limit(limit + 1) // one extra to know if there is more
+	return p, nil
+}
+
+// Resolves the fields of the cursor keys, defaults to the primary key
+func (g *Generator) cursorKeys() ([]*schema.Field, error) {
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	if len(g.Pagination.CursorKeys) == 0 {
+		if len(s.PrimaryFields) == 0 {
+			return nil, fmt.Errorf("cursor pagination requires a primary key on %s", s.Name)
+		}
+		return s.PrimaryFields, nil
+	}
+
+	fields, err := g.jsonFields()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*schema.Field, 0, len(g.Pagination.CursorKeys))
+	for _, name := range g.Pagination.CursorKeys {
+		field, exists := fields[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown cursor key %s on %s", name, s.Name)
+		}
+		// cursors are signed, not encrypted, so their values are readable by anyone
+		if restTag(field, tagWriteOnly) || g.FieldRules[name].Read != nil {
+			return nil, fmt.Errorf("cursor key %s on %s can be hidden", name, s.Name)
+		}
+		keys = append(keys, field)
+	}
+	return keys, nil
+}
+
+// Builds the keyset condition (k1, k2) > (v1, v2) as (k1 > v1) OR (k1 = v1 AND k2 > v2), reversed when paging backwards.
+func (p *cursorPage) after(from *cursor) (clause.Expression, error) {
+	var ors []clause.Expression
+	var equals []clause.Expression
+	for i, key := range p.keys {
+		value, err := parseFieldValue(key, from.Values[i])
+		if err != nil {
+			return nil, errInvalidCursor
+		}
+
+		column := clause.Column{Table: clause.CurrentTable, Name: key.DBName}
+		var compare clause.Expression = clause.Gt{Column: column, Value: value}
+		if from.Prev {
+			compare = clause.Lt{Column: column, Value: value}
+		}
+
+		ors = append(ors, clause.And(append(append([]clause.Expression{}, equals...), compare)...))
+		equals = append(equals, clause.Eq{Column: column, Value: value})
+	}
+	return clause.Or(ors...), nil
+}
+
+// Trims the extra record, restores the order when paging backwards and creates the cursors of the neighbouring pages.
+func (p *cursorPage) finish(list interface{}) {
+	results := reflect.ValueOf(list).Elem()
+	more := results.Len() > p.limit
+	if more {
+		results.Set(results.Slice(0, p.limit))
+	}
+
+	backwards := p.from != nil && p.from.Prev
+	if backwards {
+		swap := reflect.Swapper(results.Interface())
+		for i, j := 0, results.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	if results.Len() == 0 {
+		return
+	}
+	if more || backwards {
+		p.next = p.encode(results.Index(results.Len()-1), false)
+	}
+	if (more && backwards) || (p.from != nil && !backwards) {
+		p.prev = p.encode(results.Index(0), true)
+	}
+}
+
+// Builds an RFC 8288 Link header value with next and prev relations.
+func (p *cursorPage) links() string {
+	var links []string
+	if p.prev != "" {
+		links = append(links, p.link(p.prev, "prev"))
+	}
+	if p.next != "" {
+		links = append(links, p.link(p.next, "next"))
+	}
+	return strings.Join(links, ", ")
+}
+
+func (p *cursorPage) link(token string, rel string) string {
+	query := p.request.URL.Query()
+	query.Set("cursor", token)
+
+	u := url.URL{Path: p.request.URL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel)
+}
+
+// Encodes the position of a model into an opaque signed token
+func (p *cursorPage) encode(model reflect.Value, prev bool) string {
+	position := cursor{Prev: prev}
+	for _, key := range p.keys {
+		position.Values = append(position.Values, formatFieldValue(fieldValue(key, model)))
+	}
+
+	payload, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(p.sign(payload))
+}
+
+// Decodes a token, rejecting it when the signature doesn't match
+func (p *cursorPage) decode(token string) (*cursor, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, errInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, p.sign(payload)) {
+		return nil, errInvalidCursor
+	}
+
+	position := &cursor{}
+	if err := json.Unmarshal(payload, position); err != nil || len(position.Values) != len(p.keys) {
+		return nil, errInvalidCursor
+	}
+	return position, nil
+}
+
+func (p *cursorPage) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// Formats a field value so parseFieldValue can read it back
+func formatFieldValue(value interface{}) string {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	} else if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func randomSecret() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return secret
+}
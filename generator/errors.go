@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MIMEProblemJSON is the content type of RFC 7807 problem details
+const MIMEProblemJSON = "application/problem+json"
+
+// CodeValidationFailed is the code of errors holding field errors
+const CodeValidationFailed = "validation_failed"
+
+// Error is the error every handler responds with. Hooks return one to choose the response.
+type Error struct {
+	Status  int               // HTTP status, defaults to 400 Bad Request
+	Code    string            // machine readable code, defaults to the status text in snake case, i.e. "not_found"
+	Message string            // human readable detail, defaults to the status text
+	Errors  map[string]string // field errors keyed by json path
+	Fields  map[string]FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ErrorRenderer writes the response of an error, see LegacyErrorRenderer and ProblemErrorRenderer.
+type ErrorRenderer func(c *gin.Context, err *Error)
+
+// DefaultErrorRenderer renders the errors of generators without an ErrorRenderer and of the Transaction middleware.
+var DefaultErrorRenderer ErrorRenderer = LegacyErrorRenderer
+
+// LegacyErrorRenderer responds with {"message": "..."}, or a ValidationErrorResponse when there are field errors.
+func LegacyErrorRenderer(c *gin.Context, err *Error) {
+	if len(err.Errors) > 0 {
+		c.JSON(err.Status, ValidationErrorResponse{err.Message, err.Errors, err.Fields})
+	} else {
+		c.JSON(err.Status, gin.H{"message": err.Message})
+	}
+}
+
+// ProblemDetails is an RFC 7807 problem document, extended with the error code and field errors.
+type ProblemDetails struct {
+	Type   string                `json:"type"`
+	Title  string                `json:"title"`
+	Status int                   `json:"status"`
+	Detail string                `json:"detail,omitempty"`
+	Code   string                `json:"code"`
+	Errors map[string]string     `json:"errors,omitempty"`
+	Fields map[string]FieldError `json:"fields,omitempty"`
+}
+
+// ProblemErrorRenderer responds with application/problem+json ProblemDetails.
+func ProblemErrorRenderer(c *gin.Context, err *Error) {
+	c.Header("Content-Type", MIMEProblemJSON)
+	c.JSON(err.Status, ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(err.Status),
+		Status: err.Status,
+		Detail: err.Message,
+		Code:   err.Code,
+		Errors: err.Errors,
+		Fields: err.Fields,
+	})
+}
+
+// Creates an error with a status and message
+func newError(status int, message string) *Error {
+	return &Error{Status: status, Message: message}
+}
+
+// Creates a 400 Bad Request error with field errors
+func invalid(errs map[string]string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeValidationFailed, Message: "validation errors", Errors: errs}
+}
+
+// Converts an error into an *Error with its defaults set. ErrPreconditionFailed responds with 412 Precondition Failed,
+// gorm.ErrRecordNotFound with 404 Not Found and other errors with 500 Internal Server Error.
+func asError(err error) *Error {
+	var e Error
+	var target *Error
+	if errors.As(err, &target) {
+		e = *target
+	} else if errors.Is(err, ErrPreconditionFailed) {
+		e = Error{Status: http.StatusPreconditionFailed, Message: err.Error()}
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		e = Error{Status: http.StatusNotFound}
+	} else {
+		e = Error{Status: http.StatusInternalServerError, Message: err.Error()}
+	}
+
+	if e.Status == 0 {
+		e.Status = http.StatusBadRequest
+	}
+	if e.Code == "" && len(e.Errors) > 0 {
+		e.Code = CodeValidationFailed
+	} else if e.Code == "" {
+		e.Code = strings.ReplaceAll(strings.ToLower(http.StatusText(e.Status)), " ", "_")
+	}
+	if e.Message == "" {
+		e.Message = strings.ToLower(http.StatusText(e.Status))
+	}
+	return &e
+}
+
+// Aborts a request with the response of an error, see asError. Constraint violations are translated first.
+func (g *Generator) abort(c *gin.Context, err error) {
+	if e := g.constraintError(err); e != nil {
+		err = e
+	}
+	render := g.ErrorRenderer
+	if render == nil {
+		render = DefaultErrorRenderer
+	}
+	render(c, asError(err))
+	c.Abort()
+}
@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrPreconditionFailed is returned when a versioned record changed since it was fetched.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// Version of a record when it was fetched, used to make saves conditional
+type versionLock struct {
+	field *schema.Field
+	value reflect.Value
+}
+
+// Computes the strong ETag of a model, from the version field when configured or a hash of the JSON representation the
+// request receives. Fields hidden from the request are left out of the hash, so their values can't be guessed from it.
+func (g *Generator) etag(c *gin.Context, model interface{}) (string, error) {
+	if lock, err := g.versionLock(model); err != nil {
+		return "", err
+	} else if lock != nil {
+		return fmt.Sprintf(`"%v"`, lock.value.Interface()), nil
+	}
+
+	hidden, err := g.hiddenFields(c)
+	if err != nil {
+		return "", err
+	}
+	representation := model
+	if len(hidden) > 0 {
+		if representation, err = (&fieldset{all: true, hidden: hidden}).project(model); err != nil {
+			return "", err
+		}
+	}
+	raw, err := json.Marshal(representation)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// Sets the ETag header of a model
+func (g *Generator) setETag(c *gin.Context, model interface{}) error {
+	tag, err := g.etag(c, model)
+	if err == nil {
+		c.Header("ETag", tag)
+	}
+	return err
+}
+
+// Checks the If-Match header against the current model. Responds with 412 Precondition Failed when it doesn't match, or
+// 428 Precondition Required when preconditions are required but missing, and returns false.
+func (g *Generator) checkPreconditions(c *gin.Context, model interface{}) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if g.RequirePreconditions {
+			g.abort(c, newError(http.StatusPreconditionRequired, "precondition required"))
+			return false
+		}
+		return true
+	}
+
+	current, err := g.etag(c, model)
+	if err != nil {
+		g.abort(c, err)
+		return false
+	}
+	if !etagMatch(header, current, false) {
+		g.abort(c, ErrPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+// Checks if an If-Match or If-None-Match header value lists the ETag. Weak comparison ignores the W/ prefix.
+func etagMatch(header string, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate, etag = strings.TrimPrefix(candidate, "W/"), strings.TrimPrefix(etag, "W/")
+		} else if strings.HasPrefix(candidate, "W/") || strings.HasPrefix(etag, "W/") {
+			continue
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Reads the version of a model, nil when the generator has no VersionField or the value isn't a model.
+func (g *Generator) versionLock(model interface{}) (*versionLock, error) {
+	if g.VersionField == "" || reflect.TypeOf(model) != reflect.PtrTo(g.model) {
+		return nil, nil
+	}
+
+	field, err := g.versionField()
+	if err != nil {
+		return nil, err
+	}
+	value, ok := fieldReflectValue(field, reflect.ValueOf(model))
+	if !ok {
+		return nil, fmt.Errorf("unreadable version field %s", g.VersionField)
+	}
+	copied := reflect.New(value.Type()).Elem()
+	copied.Set(value)
+	return &versionLock{field: field, value: copied}, nil
+}
+
+// Looks up the version field of the model, nil when the generator has no VersionField.
+func (g *Generator) versionField() (*schema.Field, error) {
+	if g.VersionField == "" {
+		return nil, nil
+	}
+	fields, err := g.jsonFields()
+	if err != nil {
+		return nil, err
+	}
+	field, exists := fields[g.VersionField]
+	if !exists {
+		return nil, fmt.Errorf("unknown version field %s", g.VersionField)
+	}
+	return field, nil
+}
+
+// Where condition matching the version the record had when it was fetched
+func (lock *versionLock) condition() clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: lock.field.DBName}, Value: lock.value.Interface()}
+}
+
+// Sets the version of the model to the next version
+func (lock *versionLock) next(model interface{}) error {
+	target, ok := fieldReflectValue(lock.field, reflect.ValueOf(model))
+	if !ok {
+		return fmt.Errorf("unwritable version field %s", lock.field.Name)
+	}
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		target.SetInt(lock.value.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		target.SetUint(lock.value.Uint() + 1)
+	default:
+		return fmt.Errorf("version field %s must be an integer", lock.field.Name)
+	}
+	return nil
+}
+
+// Saves the model, conditioned on its version when locked and on the scope. Returns ErrPreconditionFailed when the
+// version changed.
+func (lock *versionLock) save(db *gorm.DB, model interface{}, scope scopeValues) error {
+	if lock == nil && len(scope) == 0 {
+		return db.Save(model).Error
+	}
+
+	// Save would insert the record when the conditions don't match, so update it instead
+	queryset := scope.where(db.Model(model))
+	if lock != nil {
+		if err := lock.next(model); err != nil {
+			return err
+		}
+		queryset = queryset.Where(lock.condition())
+	}
+	result := queryset.Select("*").Updates(model)
+	if result.Error == nil && lock != nil && result.RowsAffected == 0 {
+		return ErrPreconditionFailed
+	}
+	return result.Error
+}
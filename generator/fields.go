@@ -0,0 +1,228 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Fields requested with ?fields=id,name, without the fields hidden from the request
+type fieldset struct {
+	names    []string
+	fields   []*schema.Field
+	all      bool // every field was requested
+	hidden   map[string]bool
+	included []inclusionHidden // write only fields of included associations
+}
+
+// Parses the ?fields= query param. Returns nil when all fields are requested and none are hidden, or validation errors
+// keyed by query param when a field is unknown or not allowed.
+func (g *Generator) fieldset(c *gin.Context) (*fieldset, map[string]string, error) {
+	hidden, err := g.hiddenFields(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	param := c.Query("fields")
+	if param == "" {
+		if len(hidden) == 0 {
+			return nil, nil, nil
+		}
+		return &fieldset{all: true, hidden: hidden}, nil, nil
+	}
+
+	fields, err := g.jsonFields()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	set := &fieldset{hidden: hidden}
+	for _, name := range strings.Split(param, ",") {
+		field, exists := fields[name]
+		if !exists || hidden[name] || (len(g.Fields) > 0 && !allowed(g.Fields, name)) {
+			return nil, map[string]string{"fields": "unknown field " + name}, nil
+		}
+		set.names = append(set.names, name)
+		set.fields = append(set.fields, field)
+	}
+	return set, nil, nil
+}
+
+// Selects only the requested columns, along with the primary key, the version and timestamp fields the ETag and
+// Last-Modified headers are computed from, and any extra fields the generator relies on.
+func (g *Generator) selectFields(queryset *gorm.DB, set *fieldset, required ...*schema.Field) error {
+	s, err := g.schema()
+	if err != nil {
+		return err
+	}
+	version, err := g.versionField()
+	if err != nil {
+		return err
+	}
+	modified, err := g.lastModifiedField()
+	if err != nil {
+		return err
+	}
+
+	var columns []string
+	selected := make(map[string]bool)
+	for _, fields := range [][]*schema.Field{set.fields, s.PrimaryFields, {version, modified}, required} {
+		for _, field := range fields {
+			if field != nil && !selected[field.DBName] {
+				selected[field.DBName] = true
+				columns = append(columns, field.DBName)
+			}
+		}
+	}
+
+	queryset.Select(columns)
+	return nil
+}
+
+// Applies ?fields= to a listing queryset. Responds with errors and returns false when invalid.
+func (g *Generator) selectListFields(c *gin.Context, queryset *gorm.DB, p *page, inc *inclusion) (*fieldset, bool) {
+	set, errs, err := g.fieldset(c)
+	if err == nil && set != nil && !set.all {
+		required := inc.requiredFields()
+		if p != nil && p.cursor != nil {
+			required = append(required, p.cursor.keys...)
+		}
+		set.keep(inc.jsonNames()...)
+		err = g.selectFields(queryset, set, required...)
+	}
+	set = set.hideIncluded(inc)
+
+	if err != nil {
+		g.abort(c, err)
+		return nil, false
+	} else if errs != nil {
+		g.abort(c, invalid(errs))
+		return nil, false
+	}
+	return set, true
+}
+
+// Strips the keys that were not requested from the JSON representation of a model or a list of models.
+func (set *fieldset) project(value interface{}) (interface{}, error) {
+	if set == nil {
+		return value, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	raw = bytes.TrimSpace(raw)
+	if bytes.HasPrefix(raw, []byte("[")) {
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		for i := range items {
+			if items[i], err = set.pick(items[i]); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	} else if bytes.HasPrefix(raw, []byte("{")) {
+		var item map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return nil, err
+		}
+		return set.pick(item)
+	}
+	return value, nil
+}
+
+func (set *fieldset) pick(item map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	picked := item
+	if set.all {
+		for name := range set.hidden {
+			delete(item, name)
+		}
+	} else {
+		picked = make(map[string]json.RawMessage, len(set.names))
+		for _, name := range set.names {
+			if value, exists := item[name]; exists && !set.hidden[name] {
+				picked[name] = value
+			}
+		}
+	}
+
+	for _, hidden := range set.included {
+		raw, exists := picked[hidden.path[0]]
+		if !exists {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		stripKeys(value, hidden.path[1:], hidden.names)
+		stripped, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		picked[hidden.path[0]] = stripped
+	}
+	return picked, nil
+}
+
+// Deletes keys from the objects at a path of decoded JSON, walking into arrays
+func stripKeys(value interface{}, path []string, names map[string]bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			stripKeys(item, path, names)
+		}
+	case map[string]interface{}:
+		if len(path) > 0 {
+			stripKeys(v[path[0]], path[1:], names)
+			return
+		}
+		for name := range names {
+			delete(v, name)
+		}
+	}
+}
+
+// Leaves the write only fields of included associations out of the projection. Returns the fieldset, created when there
+// was none.
+func (set *fieldset) hideIncluded(inc *inclusion) *fieldset {
+	if inc == nil || len(inc.hidden) == 0 {
+		return set
+	}
+	if set == nil {
+		set = &fieldset{all: true}
+	}
+	set.included = inc.hidden
+	return set
+}
+
+// Selects the requested columns when fetching a model for a safe request. Invalid fields are left for Render to report
+// since parent models are fetched with the same query params. Models are loaded in full when a Policy or AfterFetch
+// hooks inspect them, leaving Render to project the fields.
+func (g *Generator) selectFetchFields(c *gin.Context, queryset *gorm.DB, inc *inclusion) error {
+	if (c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) || g.Policy != nil || g.AfterFetch != nil {
+		return nil
+	}
+
+	set, errs, err := g.fieldset(c)
+	if err != nil || set == nil || set.all || errs != nil {
+		return err
+	}
+	return g.selectFields(queryset, set, inc.requiredFields()...)
+}
+
+// Keeps extra json keys in the projection, i.e. included associations
+func (set *fieldset) keep(names ...string) {
+	set.names = append(set.names, names...)
+}
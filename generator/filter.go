@@ -0,0 +1,193 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// FilterOperator builds a where expression from a column and a query param value.
+type FilterOperator func(column clause.Column, field *schema.Field, value string) (clause.Expression, error)
+
+// FilterOperators available to query params, i.e. ?age[gte]=3. A param without an operator uses "eq".
+var FilterOperators = map[string]FilterOperator{
+	"eq":   compareOperator(func(c clause.Column, v interface{}) clause.Expression { return clause.Eq{Column: c, Value: v} }),
+	"ne":   compareOperator(func(c clause.Column, v interface{}) clause.Expression { return clause.Neq{Column: c, Value: v} }),
+	"gt":   compareOperator(func(c clause.Column, v interface{}) clause.Expression { return clause.Gt{Column: c, Value: v} }),
+	"gte":  compareOperator(func(c clause.Column, v interface{}) clause.Expression { return clause.Gte{Column: c, Value: v} }),
+	"lt":   compareOperator(func(c clause.Column, v interface{}) clause.Expression { return clause.Lt{Column: c, Value: v} }),
+	"lte":  compareOperator(func(c clause.Column, v interface{}) clause.Expression { return clause.Lte{Column: c, Value: v} }),
+	"like": likeOperator,
+	"in":   inOperator,
+	"null": nullOperator,
+}
+
+// Query params used by the generator itself, these are never treated as filters
+var reservedParams = map[string]bool{
+	"page":     true,
+	"per_page": true,
+	"offset":   true,
+	"limit":    true,
+	"cursor":   true,
+	"ids":      true,
+	"sort":     true,
+	"fields":   true,
+	"include":  true,
+	"trashed":  true,
+	"force":    true,
+}
+
+var filterParamRegex = regexp.MustCompile(`^([^\[\]]+)(?:\[(\w+)\])?$`)
+
+// Applies query param filters to the queryset. Returns validation errors keyed by query param.
+func (g *Generator) filter(c *gin.Context, queryset *gorm.DB) (map[string]string, error) {
+	if len(g.Filters) == 0 {
+		return nil, nil
+	}
+
+	fields, err := g.jsonFields()
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := g.hiddenFields(c)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make(map[string]string)
+	for param, values := range c.Request.URL.Query() {
+		if reservedParams[param] || strings.HasPrefix(param, "include[") {
+			continue
+		}
+
+		matches := filterParamRegex.FindStringSubmatch(param)
+		if matches == nil {
+			errs[param] = "invalid filter"
+			continue
+		}
+
+		exprs, msg := filterExprs(fields, hidden, g.Filters, matches[1], matches[2], values)
+		if msg != "" {
+			errs[param] = msg
+			continue
+		}
+		for _, expr := range exprs {
+			queryset.Where(expr)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs, nil
+	}
+	return nil, nil
+}
+
+// Builds the where expressions of a filter on a whitelisted field. Hidden fields are unknown, filtering on them would
+// reveal their values. Returns a validation message when invalid.
+func filterExprs(fields map[string]*schema.Field, hidden map[string]bool, whitelist []string, name string, op string, values []string) ([]clause.Expression, string) {
+	field, exists := fields[name]
+	if !exists || hidden[name] || !allowed(whitelist, name) {
+		return nil, "unknown field"
+	}
+
+	if op == "" {
+		op = "eq"
+	}
+	operator, exists := FilterOperators[op]
+	if !exists {
+		return nil, "unknown operator"
+	}
+
+	column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+	exprs := make([]clause.Expression, 0, len(values))
+	for _, value := range values {
+		expr, err := operator(column, field, value)
+		if err != nil {
+			return nil, err.Error()
+		}
+		exprs = append(exprs, expr)
+	}
+	return exprs, ""
+}
+
+// Creates an operator that compares the column to a single value of the field type
+func compareOperator(build func(clause.Column, interface{}) clause.Expression) FilterOperator {
+	return func(column clause.Column, field *schema.Field, value string) (clause.Expression, error) {
+		v, err := parseFieldValue(field, value)
+		if err != nil {
+			return nil, err
+		}
+		return build(column, v), nil
+	}
+}
+
+func likeOperator(column clause.Column, field *schema.Field, value string) (clause.Expression, error) {
+	return clause.Like{Column: column, Value: value}, nil
+}
+
+// Matches a comma separated list of values
+func inOperator(column clause.Column, field *schema.Field, value string) (clause.Expression, error) {
+	var values []interface{}
+	for _, item := range strings.Split(value, ",") {
+		v, err := parseFieldValue(field, item)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return clause.IN{Column: column, Values: values}, nil
+}
+
+// Matches null with true, not null with false
+func nullOperator(column clause.Column, field *schema.Field, value string) (clause.Expression, error) {
+	isNull, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bool type")
+	}
+	if isNull {
+		return clause.Eq{Column: column, Value: nil}, nil
+	}
+	return clause.Neq{Column: column, Value: nil}, nil
+}
+
+// Converts a query param value into the type of the field so databases compare it properly.
+func parseFieldValue(field *schema.Field, value string) (interface{}, error) {
+	fieldType := field.FieldType
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	var v interface{}
+	var err error
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		v, err = strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err = strconv.ParseInt(value, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err = strconv.ParseUint(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		v, err = strconv.ParseFloat(value, 64)
+	case reflect.Struct:
+		if fieldType == reflect.TypeOf(time.Time{}) {
+			v, err = time.Parse(time.RFC3339, value)
+		} else {
+			v = value
+		}
+	default:
+		v = value
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s type", fieldType.String())
+	}
+	return v, nil
+}
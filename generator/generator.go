@@ -1,14 +1,14 @@
 package generator
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
 	"reflect"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 /**
@@ -21,8 +21,9 @@ type Association struct {
 }
 
 type ValidationErrorResponse struct {
-	Message string            `json:"message"`
-	Errors  map[string]string `json:"errors"`
+	Message string                `json:"message"`
+	Errors  map[string]string     `json:"errors"`
+	Fields  map[string]FieldError `json:"fields,omitempty"` // details of failed validations by the same keys
 }
 
 // ResolverFn resolves the queryset and returns whether to continue or not. Use the context to respond with errors if needed.
@@ -36,6 +37,85 @@ type Generator struct {
 	model  reflect.Type
 	models reflect.Type
 	Param  string
+
+	// Resolves the database of a request, i.e. a tenant database, a read replica or a session. Defaults to DB with the
+	// request context, so cancellations and deadlines propagate into queries. A Transaction middleware takes precedence.
+	DBResolver func(*gin.Context) *gorm.DB
+
+	// Restricts every query to the rows of a request, i.e. of a tenant, and stamps its values onto created and updated
+	// records. Records outside of the scope are not found.
+	Scope ScopeFn
+
+	// Authorizes the actions of the handlers, Fetch checks CanRead since the record is rendered by every route. Denied
+	// requests respond with 403 Forbidden, or 404 Not Found for records when HideForbidden is set.
+	Policy        Policy
+	HideForbidden bool
+
+	// Field permissions keyed by json name, i.e. {"salary": {Read: Roles("admin"), Write: Roles("admin")}}. Fields a
+	// request can't read are left out of responses. Writes to fields it can't write are dropped, or rejected with 422
+	// Unprocessable Entity when RejectForbiddenFields is set.
+	FieldRules            map[string]FieldRule
+	RejectForbiddenFields bool
+
+	// Allows purging soft deleted models with DELETE ?force=true, i.e. Roles("admin"). Purging is forbidden when nil.
+	ForceDelete Permission
+
+	// Json field names that can be filtered with query params, i.e. ?species=cat&age[gte]=3. "*" allows all fields.
+	// Filtering is disabled when empty.
+	Filters []string
+
+	// Json field names that can be sorted with ?sort=-age,name. "*" allows all fields. Defaults to indexed fields.
+	Sorts []string
+
+	// Json field names that can be requested with ?fields=id,name. "*" or empty allows all fields.
+	Fields []string
+
+	// Associations that can be embedded with ?include=, keyed by json path, i.e. "animals" or "animals.owner". A "*" key
+	// allows any association. IncludeDepth limits nesting, defaults to 2. Included records leave out their write only
+	// fields, the field rules, scope and policy of their own generators don't apply.
+	Includes     map[string]Include
+	IncludeDepth int
+
+	// Json name of an integer version column. ETags are derived from it and saves are conditioned on it so concurrent
+	// updates can't overwrite each other. ETags hash the record when empty.
+	VersionField string
+
+	// Responds with 428 Precondition Required when Update, Patch or Delete requests have no If-Match header
+	RequirePreconditions bool
+
+	// Creates the record with the key of the path when PUT finds none, responding with 201 Created instead of 404 Not
+	// Found. Existing records are replaced as usual. Clients own the keys, i.e. when syncing from another system.
+	Upsert bool
+
+	// Json name of a timestamp field sent as Last-Modified and compared against If-Modified-Since. Defaults to UpdatedAt.
+	LastModifiedField string
+
+	// Business logic run by the handlers, i.e. g.BeforeCreate = func(c *gin.Context, tx *gorm.DB, model interface{}) error
+	Hooks
+
+	// Writes the error responses of the handlers, i.e. ProblemErrorRenderer. Defaults to DefaultErrorRenderer.
+	ErrorRenderer ErrorRenderer
+
+	// Paginates listings when set. Results are returned unpaginated when nil.
+	Pagination *Pagination
+
+	// Deduplicates Create requests retried with the same Idempotency-Key header when set, i.e.
+	// NewMemoryIdempotencyStore(24 * time.Hour). The first response is replayed to retries.
+	Idempotency IdempotencyStore
+
+	// Namespaces idempotency keys per principal, i.e. the user or API client of the request, so nobody is replayed the
+	// response of another. Keys are namespaced by the Scope of the request when nil.
+	IdempotencyScope func(c *gin.Context) string
+
+	// Enables the bulk handlers of Handlers when set, see BulkCreate, BulkPatch and BulkDelete.
+	Bulk *Bulk
+
+	// factories used instead of reflection when the model type is known at compile time, see NewOf
+	newFn      func() interface{}
+	newSliceFn func() interface{}
+
+	// config of the first database resolved by a generator without DB, which the model schema is parsed with
+	resolved atomic.Value
 }
 
 func New(db *gorm.DB, model interface{}, paramName string) *Generator {
@@ -43,81 +123,200 @@ func New(db *gorm.DB, model interface{}, paramName string) *Generator {
 	return &Generator{DB: db, model: mt, models: reflect.SliceOf(mt), Param: paramName}
 }
 
-func (g *Generator) bindAndValidate(c *gin.Context, model interface{}) map[string]string {
-	if err := c.ShouldBindJSON(model); err != nil {
-		// check if err is a validator error
-		if ve, ok := err.(validator.ValidationErrors); ok {
-			errors := make(map[string]string)
-			for _, fieldErr := range ve {
-				errors[fieldErr.Field()] = fieldErr.Tag()
-			}
-			return errors
-		} else if te, ok := err.(*json.UnmarshalTypeError); ok {
-			return map[string]string{te.Field: "invalid " + te.Type.String() + " type"}
-		} else {
-			return map[string]string{"error": err.Error()}
-		}
+func (g *Generator) bindAndValidate(c *gin.Context, model interface{}) *Error {
+	if err := bindJSON(c, model); err != nil {
+		return g.validationError(c, err)
+	} else if err := validateStruct(model); err != nil {
+		return g.validationError(c, err)
 	}
 	return nil
 }
 
 // Create an instance of model
 func (g *Generator) new() interface{} {
+	if g.newFn != nil {
+		return g.newFn()
+	}
 	return reflect.New(g.model).Interface()
 }
 
 // Creates a slice of models
 func (g *Generator) newSlice() interface{} {
+	if g.newSliceFn != nil {
+		return g.newSliceFn()
+	}
 	return reflect.New(g.models).Interface()
 }
 
+// Retrieves the database of a request, which is the request transaction when there is one.
+func (g *Generator) db(c *gin.Context) *gorm.DB {
+	db := g.resolveDB(c)
+	if g.DB == nil && db != nil && g.resolved.Load() == nil {
+		g.resolved.Store(db.Config)
+	}
+	return db
+}
+
+func (g *Generator) resolveDB(c *gin.Context) *gorm.DB {
+	if tx, ok := c.Get(TxKey); ok {
+		if tx, ok := tx.(*gorm.DB); ok {
+			return tx
+		}
+	}
+	if g.DBResolver != nil {
+		return g.DBResolver(c)
+	}
+	return g.DB.WithContext(c.Request.Context())
+}
+
+// Applies the built-in query param features to a listing queryset. Responds with errors and returns false when the query is invalid.
+func (g *Generator) resolve(c *gin.Context, queryset *gorm.DB) (ok bool) {
+	for _, apply := range []func(*gin.Context, *gorm.DB) (map[string]string, error){g.trashed, g.filter, g.sort} {
+		errs, err := apply(c, queryset)
+		if err != nil {
+			g.abort(c, err)
+			return false
+		} else if errs != nil {
+			g.abort(c, invalid(errs))
+			return false
+		}
+	}
+	return true
+}
+
 // Creates a listing handler. Resolvers is a function that can be used to fine-tune the queryset or add pagination.
 func (g *Generator) List(resolvers ResolverFn) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if g.Policy != nil && !g.Policy.CanList(c) {
+			g.abortForbidden(c, false)
+			return
+		}
 		instList := g.newSlice()
 
 		// Resolvers
-		queryset := g.DB.Model(instList)
+		scope, ok := g.scope(c)
+		if !ok {
+			return
+		}
+		queryset := scope.where(g.db(c).Model(instList))
+		if ok := g.resolve(c, queryset); !ok {
+			return
+		}
 		if resolvers != nil {
 			if ok := resolvers(c, queryset); !ok {
 				return
 			}
 		}
+		page, ok := g.paginate(c, queryset, func(qs *gorm.DB) (count int64, err error) {
+			err = qs.Count(&count).Error
+			return
+		})
+		if !ok {
+			return
+		}
+
+		included, ok := g.includeList(c, queryset)
+		if !ok {
+			return
+		}
+		fields, ok := g.selectListFields(c, queryset, page, included)
+		if !ok {
+			return
+		}
 
 		// Perform
 		if err := queryset.Find(instList).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			g.abort(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, instList)
+		included.trim(instList)
+		g.renderList(c, instList, page, fields)
 	}
 }
 
 // Creates an associated listing handler. This is used for child relationships of a parent association. Resolvers is a function that can be used to fine-tune the queryset or add pagination.
 func (g *Generator) ListAssociated(assoc Association, resolvers ResolverFn) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if g.Policy != nil && !g.Policy.CanList(c) {
+			g.abortForbidden(c, false)
+			return
+		}
 		instList := g.newSlice()
 
 		// Resolvers
-		queryset := g.DB.Model(c.MustGet(assoc.ParentName))
+		scope, ok := g.scope(c)
+		if !ok {
+			return
+		}
+		queryset := scope.where(g.db(c).Model(c.MustGet(assoc.ParentName)))
+		if ok := g.resolve(c, queryset); !ok {
+			return
+		}
 		if resolvers != nil {
 			resolvers(c, queryset)
 		}
+		page, ok := g.paginate(c, queryset, func(qs *gorm.DB) (int64, error) {
+			association := qs.Association(assoc.Association)
+			count := association.Count()
+			return count, association.Error
+		})
+		if !ok {
+			return
+		}
+
+		included, ok := g.includeList(c, queryset)
+		if !ok {
+			return
+		}
+		fields, ok := g.selectListFields(c, queryset, page, included)
+		if !ok {
+			return
+		}
 
 		// Perform
 		if err := queryset.Association(assoc.Association).Find(instList); err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err})
+			g.abort(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, instList)
+		included.trim(instList)
+		g.renderList(c, instList, page, fields)
 	}
 }
 
 // Creates a rendering handler. This is used for rendering a model to JSON
 func (g *Generator) Render() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if model, exists := c.Get(g.Param); !exists {
-			c.AbortWithStatus(http.StatusNotFound)
+		model, exists := c.Get(g.Param)
+		if !exists {
+			g.abort(c, gorm.ErrRecordNotFound)
+			return
+		}
+
+		fields, errs, err := g.fieldset(c)
+		if err == nil && errs == nil {
+			var included *inclusion
+			if included, errs, err = g.include(c); err == nil && errs == nil && fields != nil {
+				fields.keep(included.jsonNames()...)
+			}
+			fields = fields.hideIncluded(included)
+		}
+		var etag string
+		var notModified bool
+		if err == nil && errs == nil {
+			etag, err = g.etag(c, model)
+		}
+		if err == nil && errs == nil {
+			notModified, err = g.notModified(c, etag, model)
+		}
+		if err == nil && errs == nil && !notModified {
+			model, err = fields.project(model)
+		}
+		if err != nil {
+			g.abort(c, err)
+		} else if errs != nil {
+			g.abort(c, invalid(errs))
+		} else if notModified {
+			c.AbortWithStatus(http.StatusNotModified)
 		} else {
 			c.JSON(c.Writer.Status(), model)
 		}
@@ -128,16 +327,48 @@ func (g *Generator) Render() gin.HandlerFunc {
 func (g *Generator) Fetch() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Param(g.Param) == "" {
-			c.AbortWithStatus(http.StatusNotFound)
+			g.abort(c, gorm.ErrRecordNotFound)
 			return
 		}
 
+		scope, ok := g.scope(c)
+		if !ok {
+			return
+		}
 		inst := g.new()
-		if err := g.DB.Take(inst, c.Param(g.Param)).Error; errors.Is(err, gorm.ErrRecordNotFound) {
-			c.AbortWithStatus(http.StatusNotFound)
+		queryset := scope.where(g.db(c).Model(inst))
+		if g.purging(c) {
+			queryset = queryset.Unscoped()
+		}
+		included, err := g.includeFetch(c, queryset)
+		if err == nil {
+			err = g.selectFetchFields(c, queryset, included)
+		}
+		if err != nil {
+			g.abort(c, err)
+			return
+		}
+
+		if err := queryset.Take(inst, c.Param(g.Param)).Error; errors.Is(err, gorm.ErrRecordNotFound) && g.upserting(c) {
+			return // Update creates it
+		} else if errors.Is(err, gorm.ErrRecordNotFound) {
+			g.abort(c, gorm.ErrRecordNotFound)
 		} else if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			g.abort(c, err)
 		} else {
+			included.trim(inst)
+			if g.Policy != nil && !g.Policy.CanRead(c, inst) {
+				g.abortForbidden(c, true)
+				return
+			}
+			if err := g.AfterFetch.run(c, g.db(c), inst); err != nil {
+				g.abort(c, err)
+				return
+			}
+			if err := g.setETag(c, inst); err != nil {
+				g.abort(c, err)
+				return
+			}
 			c.Set(g.Param, inst)
 		}
 	}
@@ -147,20 +378,50 @@ func (g *Generator) Fetch() gin.HandlerFunc {
 func (g *Generator) FetchAssociated(assoc Association) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Param(g.Param) == "" {
-			c.AbortWithStatus(http.StatusNotFound)
+			g.abort(c, gorm.ErrRecordNotFound)
 			return
 		}
 
+		scope, ok := g.scope(c)
+		if !ok {
+			return
+		}
 		inst := g.new()
+		queryset := scope.where(g.db(c).Model(c.MustGet(assoc.ParentName)))
+		counter := scope.where(g.db(c).Model(c.MustGet(assoc.ParentName)).Where(c.Param(g.Param)))
+		if g.purging(c) {
+			queryset, counter = queryset.Unscoped(), counter.Unscoped()
+		}
+		included, err := g.includeFetch(c, queryset)
+		if err == nil {
+			err = g.selectFetchFields(c, queryset, included)
+		}
+		if err != nil {
+			g.abort(c, err)
+			return
+		}
 
 		// FIXME: gorm doesn't return error when record not found, so do a COUNT first
-		if count := g.DB.Model(c.MustGet(assoc.ParentName)).Where(c.Param(g.Param)).Association(assoc.Association).Count(); count != 1 {
-			c.AbortWithStatus(http.StatusNotFound)
-		} else if err := g.DB.Model(c.MustGet(assoc.ParentName)).Association(assoc.Association).Find(inst, c.Param(g.Param)); errors.Is(err, gorm.ErrRecordNotFound) {
-			c.AbortWithStatus(http.StatusNotFound)
+		if count := counter.Association(assoc.Association).Count(); count != 1 {
+			g.abort(c, gorm.ErrRecordNotFound)
+		} else if err := queryset.Association(assoc.Association).Find(inst, c.Param(g.Param)); errors.Is(err, gorm.ErrRecordNotFound) {
+			g.abort(c, gorm.ErrRecordNotFound)
 		} else if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err})
+			g.abort(c, err)
 		} else {
+			included.trim(inst)
+			if g.Policy != nil && !g.Policy.CanRead(c, inst) {
+				g.abortForbidden(c, true)
+				return
+			}
+			if err := g.AfterFetch.run(c, g.db(c), inst); err != nil {
+				g.abort(c, err)
+				return
+			}
+			if err := g.setETag(c, inst); err != nil {
+				g.abort(c, err)
+				return
+			}
 			c.Set(g.Param, inst)
 		}
 	}
@@ -168,66 +429,151 @@ func (g *Generator) FetchAssociated(assoc Association) gin.HandlerFunc {
 
 // Creates a handler to create a model and store it into the context.
 func (g *Generator) Create() gin.HandlerFunc {
-	return func(c *gin.Context) {
+	return g.idempotent(func(c *gin.Context) {
 		inst := g.new()
-		if errs := g.bindAndValidate(c, inst); errs != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, ValidationErrorResponse{"validation errors", errs})
+		if err := g.bindAndValidate(c, inst); err != nil {
+			g.abort(c, err)
+			return
+		}
+		scope, ok := g.scope(c)
+		if !ok {
+			return
+		} else if errs, err := g.guardWrites(c, inst, nil, fieldNonZero(inst)); !g.abortGuardWrites(c, errs, err) {
+			return
+		} else if err := scope.stamp(inst); err != nil {
+			g.abort(c, err)
+			return
+		}
+		if g.Policy != nil && !g.Policy.CanCreate(c, inst) {
+			g.abortForbidden(c, false)
 			return
 		}
 
-		if err := g.DB.Create(inst).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		err := g.db(c).Transaction(func(tx *gorm.DB) error {
+			if err := g.BeforeCreate.run(c, tx, inst); err != nil {
+				return err
+			}
+			if err := tx.Create(inst).Error; err != nil {
+				return err
+			}
+			return g.AfterCreate.run(c, tx, inst)
+		})
+		if err != nil {
+			g.abort(c, err)
 			return
 		}
 
 		c.Status(http.StatusCreated)
 		c.Set(g.Param, inst)
-	}
+	})
 }
 
 // Creates an associated handler to create a child model from a parent relationship.
 func (g *Generator) CreateAssociated(assoc Association) gin.HandlerFunc {
-	return func(c *gin.Context) {
+	return g.idempotent(func(c *gin.Context) {
 		inst := g.new()
-		if errs := g.bindAndValidate(c, &inst); errs != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, ValidationErrorResponse{"validation errors", errs})
+		if err := g.bindAndValidate(c, &inst); err != nil {
+			g.abort(c, err)
 			return
 		}
-
-		if err := g.DB.Model(inst).Create(inst).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		scope, ok := g.scope(c)
+		if !ok {
+			return
+		} else if errs, err := g.guardWrites(c, inst, nil, fieldNonZero(inst)); !g.abortGuardWrites(c, errs, err) {
+			return
+		} else if err := scope.stamp(inst); err != nil {
+			g.abort(c, err)
+			return
+		}
+		if g.Policy != nil && !g.Policy.CanCreate(c, inst) {
+			g.abortForbidden(c, false)
 			return
 		}
 
-		if err := g.DB.Model(c.MustGet(assoc.ParentName)).Association(assoc.Association).Append(inst); err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err})
+		err := g.db(c).Transaction(func(tx *gorm.DB) error {
+			if err := g.BeforeCreate.run(c, tx, inst); err != nil {
+				return err
+			}
+			if err := tx.Model(inst).Create(inst).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(c.MustGet(assoc.ParentName)).Association(assoc.Association).Append(inst); err != nil {
+				return err
+			}
+			return g.AfterCreate.run(c, tx, inst)
+		})
+		if err != nil {
+			g.abort(c, err)
 			return
 		}
 
 		c.Status(http.StatusCreated)
 		c.Set(g.Param, inst)
-	}
+	})
 }
 
-// Creates a handler that updates a single record and stores it into the context.
+// Creates a handler that updates a single record and stores it into the context. When mergeFunc is nil, writable fields
+// are copied from the input to the record, see the `rest` struct tag. Missing records are created with Upsert.
 func (g *Generator) Update(mergeFunc MergerFn) gin.HandlerFunc {
+	if mergeFunc == nil {
+		mergeFunc = g.mergeFields
+	}
 	return func(c *gin.Context) {
+		if _, exists := c.Get(g.Param); !exists && g.upserting(c) {
+			g.createWithKey(c)
+			return
+		}
+
 		inst := g.new()
 		dest := c.MustGet(g.Param)
+		if ok := g.checkPreconditions(c, dest); !ok {
+			return
+		}
+		scope, ok := g.scope(c)
+		if !ok {
+			return
+		}
+		lock, err := g.versionLock(dest)
+		if err != nil {
+			g.abort(c, err)
+			return
+		}
 
-		if errs := g.bindAndValidate(c, inst); errs != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, ValidationErrorResponse{"validation errors", errs})
+		if err := g.bindAndValidate(c, inst); err != nil {
+			g.abort(c, err)
 			return
 		}
 
 		// Merge
+		old := g.copy(dest)
 		if err := mergeFunc(inst, dest); err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			g.abort(c, newError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		written := func(field *schema.Field) bool { return fieldNonZero(inst)(field) && fieldChanged(inst, old)(field) }
+		if errs, err := g.guardWrites(c, dest, old, written); !g.abortGuardWrites(c, errs, err) {
+			return
+		}
+		if err := scope.stamp(dest); err != nil {
+			g.abort(c, err)
+			return
+		}
+		if g.Policy != nil && !g.Policy.CanUpdate(c, old, dest) {
+			g.abortForbidden(c, true)
 			return
 		}
 
-		if err := g.DB.Save(dest).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		err = g.db(c).Transaction(func(tx *gorm.DB) error {
+			if err := g.BeforeUpdate.run(c, tx, old, dest); err != nil {
+				return err
+			}
+			if err := lock.save(tx, dest, scope); err != nil {
+				return err
+			}
+			return g.AfterUpdate.run(c, tx, old, dest)
+		})
+		if err != nil {
+			g.abort(c, err)
 			return
 		}
 
@@ -235,12 +581,38 @@ func (g *Generator) Update(mergeFunc MergerFn) gin.HandlerFunc {
 	}
 }
 
-// Creates a deletion handler that deletes a model and responds with 204 No Content.
+// Creates a deletion handler that deletes a model and responds with 204 No Content. Soft deleted models are purged with
+// ?force=true when the ForceDelete permission allows it.
 func (g *Generator) Delete() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		model := c.MustGet(g.Param)
-		if err := g.DB.Delete(model).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		if g.Policy != nil && !g.Policy.CanDelete(c, model) {
+			g.abortForbidden(c, true)
+			return
+		}
+		force := forceRequested(c)
+		if force && !g.purging(c) {
+			g.abortForbidden(c, false)
+			return
+		}
+		if ok := g.checkPreconditions(c, model); !ok {
+			return
+		}
+		scope, ok := g.scope(c)
+		if !ok {
+			return
+		}
+		lock, err := g.versionLock(model)
+		if err != nil {
+			g.abort(c, err)
+			return
+		}
+
+		err = g.db(c).Transaction(func(tx *gorm.DB) error {
+			return g.deleteModel(c, tx, scope, lock, force, model)
+		})
+		if err != nil {
+			g.abort(c, err)
 			return
 		}
 
@@ -248,16 +620,53 @@ func (g *Generator) Delete() gin.HandlerFunc {
 	}
 }
 
+// Runs the delete hooks and deletes a model within its scope, or purges it when forced.
+func (g *Generator) deleteModel(c *gin.Context, tx *gorm.DB, scope scopeValues, lock *versionLock, force bool, model interface{}) error {
+	if err := g.BeforeDelete.run(c, tx, model); err != nil {
+		return err
+	}
+	queryset := scope.where(tx)
+	if force {
+		queryset = queryset.Unscoped()
+	}
+	if lock != nil {
+		queryset = queryset.Where(lock.condition())
+	}
+	if result := queryset.Delete(model); result.Error != nil {
+		return result.Error
+	} else if lock != nil && result.RowsAffected == 0 {
+		return ErrPreconditionFailed
+	} else if result.RowsAffected == 0 && len(scope) > 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return g.AfterDelete.run(c, tx, model)
+}
+
 // Handy function to create boilerplate handlers for CRUD operations.
 func (g *Generator) Handlers(resolvers ResolverFn, mergeFn MergerFn) *Handlers {
-	return &Handlers{
-		Param:  g.Param,
-		List:   g.List(resolvers),
-		Fetch:  g.Fetch(),
-		Create: g.Create(),
-		Update: g.Update(mergeFn),
-		Delete: g.Delete(),
+	h := &Handlers{
+		Param:   g.Param,
+		List:    g.List(resolvers),
+		Fetch:   g.Fetch(),
+		Render:  g.Render(),
+		Create:  g.Create(),
+		Update:  g.Update(mergeFn),
+		Patch:   g.Patch(),
+		Delete:  g.Delete(),
+		Restore: g.restoreHandler(),
+	}
+	if g.Bulk != nil {
+		h.BulkCreate, h.BulkPatch, h.BulkDelete = g.BulkCreate(), g.BulkPatch(), g.BulkDelete()
+	}
+	return h
+}
+
+// Creates the Restore handler of soft deleted models, nil otherwise
+func (g *Generator) restoreHandler() gin.HandlerFunc {
+	if field, err := g.deletedAtField(); err != nil || field == nil {
+		return nil
 	}
+	return g.Restore()
 }
 
 // Handy function to create boilderplate handlers for CRUD operations with associations.
@@ -266,8 +675,10 @@ func (g *Generator) AssociatedHandlers(assoc Association, resolvers ResolverFn,
 		Param:  g.Param,
 		List:   g.ListAssociated(assoc, resolvers),
 		Fetch:  g.FetchAssociated(assoc),
+		Render: g.Render(),
 		Create: g.CreateAssociated(assoc),
 		Update: g.Update(mergerFn),
+		Patch:  g.Patch(),
 		Delete: g.Delete(),
 	}
 }
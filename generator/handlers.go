@@ -3,13 +3,20 @@ package generator
 import "github.com/gin-gonic/gin"
 
 type Handlers struct {
-	Param  string
-	List   gin.HandlerFunc
-	Fetch  gin.HandlerFunc
-	Render gin.HandlerFunc
-	Create gin.HandlerFunc
-	Update gin.HandlerFunc
-	Delete gin.HandlerFunc
+	Param   string
+	List    gin.HandlerFunc
+	Fetch   gin.HandlerFunc
+	Render  gin.HandlerFunc
+	Create  gin.HandlerFunc
+	Update  gin.HandlerFunc
+	Patch   gin.HandlerFunc
+	Delete  gin.HandlerFunc
+	Restore gin.HandlerFunc // soft deleted models only
+
+	// Registered when set, see Generator.Bulk
+	BulkCreate gin.HandlerFunc
+	BulkPatch  gin.HandlerFunc
+	BulkDelete gin.HandlerFunc
 }
 
 // Register boilderplate handler functions for CRUD operations.
@@ -17,9 +24,22 @@ func (h *Handlers) Register(app *gin.Engine, path string, middlewares ...gin.Han
 	group := app.Group(path, middlewares...)
 	group.GET("", h.List)
 	group.POST("", h.Create, h.Render)
+	if h.BulkCreate != nil {
+		group.POST("/bulk", h.BulkCreate)
+	}
+	if h.BulkPatch != nil {
+		group.PATCH("/bulk", h.BulkPatch)
+	}
+	if h.BulkDelete != nil {
+		group.DELETE("", h.BulkDelete)
+	}
 	group.GET("/:"+h.Param, h.Fetch, h.Render)
 	group.PUT("/:"+h.Param, h.Fetch, h.Update, h.Render)
-	group.DELETE("/:"+h.Param, h.Delete)
+	group.PATCH("/:"+h.Param, h.Fetch, h.Patch, h.Render)
+	group.DELETE("/:"+h.Param, h.Fetch, h.Delete)
+	if h.Restore != nil {
+		group.POST("/:"+h.Param+"/restore", h.Restore, h.Render)
+	}
 
 	return group
 }
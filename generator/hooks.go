@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HookFn runs business logic around a handler with the transaction of the handler. Returning an error aborts the
+// request and rolls back the transaction, use an *Error to choose the response.
+type HookFn func(c *gin.Context, tx *gorm.DB, model interface{}) error
+
+// UpdateHookFn is a HookFn for updates, which also receives a snapshot of the model before the change.
+type UpdateHookFn func(c *gin.Context, tx *gorm.DB, old interface{}, model interface{}) error
+
+// Hooks run by the generated handlers. AfterFetch receives the request DB rather than a transaction of its own.
+type Hooks struct {
+	BeforeCreate HookFn
+	AfterCreate  HookFn
+	BeforeUpdate UpdateHookFn
+	AfterUpdate  UpdateHookFn
+	BeforeDelete HookFn
+	AfterDelete  HookFn
+	AfterFetch   HookFn
+}
+
+func (fn HookFn) run(c *gin.Context, tx *gorm.DB, model interface{}) error {
+	if fn == nil {
+		return nil
+	}
+	return fn(c, tx, model)
+}
+
+func (fn UpdateHookFn) run(c *gin.Context, tx *gorm.DB, old interface{}, model interface{}) error {
+	if fn == nil {
+		return nil
+	}
+	return fn(c, tx, old, model)
+}
@@ -0,0 +1,287 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyKeyHeader is the request header that identifies retries of a create request
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyReplayedHeader is set on responses replayed from an IdempotencyStore
+const IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+// IdempotencyRecord is the state of an idempotency key. Status is 0 while its request is in progress.
+type IdempotencyRecord struct {
+	Fingerprint string // hash of the method, path and body of the request
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyStore records the responses of create requests by idempotency key. Implementations must reserve keys
+// atomically, since concurrent retries race for them.
+type IdempotencyStore interface {
+	// Reserves a key for a request in progress. Returns nil when it was reserved, or the record already stored.
+	Reserve(ctx context.Context, key string, fingerprint string) (*IdempotencyRecord, error)
+
+	// Records the response of a reserved key
+	Complete(ctx context.Context, key string, record IdempotencyRecord) error
+
+	// Releases a reserved key whose request failed, so it can be retried
+	Release(ctx context.Context, key string) error
+}
+
+// Wraps a create handler to deduplicate requests with an Idempotency-Key header. The first response is recorded once
+// the rest of the handler chain ran, or after the commit within the Transaction middleware, retries are answered from
+// the store. A key in progress responds with 409 Conflict, and a key reused with another request with 422
+// Unprocessable Entity. Server errors and failed commits release the key.
+func (g *Generator) idempotent(handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if g.Idempotency == nil || key == "" {
+			handler(c)
+			return
+		}
+		key, ok := g.idempotencyKey(c, key)
+		if !ok {
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			g.abort(c, newError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		ctx := c.Request.Context()
+		record, err := g.Idempotency.Reserve(ctx, key, fingerprint)
+		if err != nil {
+			g.abort(c, err)
+			return
+		} else if record != nil && record.Status == 0 {
+			g.abort(c, newError(http.StatusConflict, "a request with this idempotency key is in progress"))
+			return
+		} else if record != nil && record.Fingerprint != fingerprint {
+			g.abort(c, newError(http.StatusUnprocessableEntity, "idempotency key reused with a different request"))
+			return
+		} else if record != nil {
+			c.Header(IdempotencyReplayedHeader, "true")
+			c.Data(record.Status, record.ContentType, record.Body)
+			c.Abort()
+			return
+		}
+
+		// released when the chain panics
+		handled := false
+		defer func() {
+			if !handled {
+				g.Idempotency.Release(ctx, key)
+			}
+		}()
+
+		recorder := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		handler(c)
+		if !c.IsAborted() {
+			c.Next()
+		}
+		c.Writer = recorder.ResponseWriter
+		handled = true
+
+		afterTransaction(c, func(err error) {
+			status := recorder.Status()
+			if err == nil && status < http.StatusInternalServerError {
+				err = g.Idempotency.Complete(ctx, key, IdempotencyRecord{fingerprint, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes()})
+				if err == nil {
+					return
+				}
+				c.Error(err)
+			}
+			g.Idempotency.Release(ctx, key)
+		})
+	}
+}
+
+// Prefixes an idempotency key with a hash of its namespace, see IdempotencyScope. Responds with the error of the scope
+// and returns false when it fails.
+func (g *Generator) idempotencyKey(c *gin.Context, key string) (string, bool) {
+	var namespace string
+	if g.IdempotencyScope != nil {
+		namespace = g.IdempotencyScope(c)
+	} else if g.Scope != nil {
+		values, err := g.Scope(c)
+		if err != nil {
+			g.abort(c, err)
+			return "", false
+		}
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			namespace += fmt.Sprintf("%s=%v\n", name, values[name])
+		}
+	}
+
+	if namespace == "" {
+		return key, true
+	}
+	sum := sha256.Sum256([]byte(namespace))
+	return hex.EncodeToString(sum[:8]) + ":" + key, true
+}
+
+// Copies the body written to a response
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// MemoryIdempotencyStore keeps idempotency keys in memory for a single process. Keys expire after the TTL.
+type MemoryIdempotencyStore struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	records map[string]*memoryIdempotencyRecord
+	swept   time.Time
+}
+
+type memoryIdempotencyRecord struct {
+	IdempotencyRecord
+	created time.Time
+}
+
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{TTL: ttl, records: make(map[string]*memoryIdempotencyRecord)}
+}
+
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, key string, fingerprint string) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.TTL > 0 && now.Sub(s.swept) > s.TTL {
+		for k, record := range s.records {
+			if now.Sub(record.created) > s.TTL {
+				delete(s.records, k)
+			}
+		}
+		s.swept = now
+	}
+
+	if record, exists := s.records[key]; exists && (s.TTL <= 0 || now.Sub(record.created) <= s.TTL) {
+		copied := record.IdempotencyRecord
+		return &copied, nil
+	}
+	s.records[key] = &memoryIdempotencyRecord{IdempotencyRecord{Fingerprint: fingerprint}, now}
+	return nil, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string, record IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reserved, exists := s.records[key]
+	if !exists {
+		return errors.New("idempotency key is not reserved")
+	}
+	reserved.IdempotencyRecord = record
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// IdempotencyKey is the table of a GormIdempotencyStore
+type IdempotencyKey struct {
+	Key         string `gorm:"primaryKey;size:255"`
+	Fingerprint string `gorm:"size:64"`
+	Status      int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+}
+
+// GormIdempotencyStore keeps idempotency keys in a database table shared by every process. Keys expire after the TTL.
+type GormIdempotencyStore struct {
+	DB  *gorm.DB
+	TTL time.Duration
+}
+
+// NewGormIdempotencyStore creates a store and migrates its idempotency_keys table.
+func NewGormIdempotencyStore(db *gorm.DB, ttl time.Duration) (*GormIdempotencyStore, error) {
+	if err := db.AutoMigrate(&IdempotencyKey{}); err != nil {
+		return nil, err
+	}
+	return &GormIdempotencyStore{DB: db, TTL: ttl}, nil
+}
+
+func (s *GormIdempotencyStore) Reserve(ctx context.Context, key string, fingerprint string) (*IdempotencyRecord, error) {
+	db := s.DB.WithContext(ctx)
+	byKey := clause.Eq{Column: clause.Column{Name: "key"}, Value: key}
+	if s.TTL > 0 {
+		expired := clause.Lt{Column: clause.Column{Name: "created_at"}, Value: time.Now().Add(-s.TTL)}
+		if err := db.Where(byKey).Where(expired).Delete(&IdempotencyKey{}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&IdempotencyKey{Key: key, Fingerprint: fingerprint})
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	stored := IdempotencyKey{}
+	if err := db.Where(byKey).Take(&stored).Error; err != nil {
+		return nil, err
+	}
+	return &IdempotencyRecord{stored.Fingerprint, stored.Status, stored.ContentType, stored.Body}, nil
+}
+
+func (s *GormIdempotencyStore) Complete(ctx context.Context, key string, record IdempotencyRecord) error {
+	return s.DB.WithContext(ctx).Model(&IdempotencyKey{}).Where(clause.Eq{Column: clause.Column{Name: "key"}, Value: key}).Updates(map[string]interface{}{
+		"status":       record.Status,
+		"content_type": record.ContentType,
+		"body":         record.Body,
+	}).Error
+}
+
+func (s *GormIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.DB.WithContext(ctx).Where(clause.Eq{Column: clause.Column{Name: "key"}, Value: key}).Delete(&IdempotencyKey{}).Error
+}
@@ -0,0 +1,337 @@
+package generator
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Include declares an association that clients can embed with ?include=, i.e. ?include=animals,animals.owner
+type Include struct {
+	// Json fields of the associated model that can be filtered, i.e. ?include[animals][species]=cat
+	Filters []string
+	// Largest number of associated records per parent, also the limit when ?include[animals][limit]= isn't given. 0 for
+	// no maximum.
+	MaxLimit int
+}
+
+var includeParamRegex = regexp.MustCompile(`^include\[([\w.]+)\]\[(\w+)\](?:\[(\w+)\])?$`)
+
+// Associations requested with ?include=
+type inclusion struct {
+	names    []string        // top level json names of the included associations
+	required []*schema.Field // fields of the model that associations are loaded by
+	preloads []inclusionPreload
+	limits   []inclusionLimit // per parent limits of has many associations
+	hidden   []inclusionHidden
+}
+
+// Write only fields of the records included at a json path, i.e. owner.animals
+type inclusionHidden struct {
+	path  []string
+	names map[string]bool
+}
+
+type inclusionPreload struct {
+	path       []*schema.Relationship
+	conditions []clause.Expression
+}
+
+type inclusionLimit struct {
+	path  []*schema.Relationship
+	limit int
+}
+
+// Parses ?include= into the associations to preload. Returns validation errors keyed by query param when an association
+// is not includable, too deep or filtered incorrectly.
+func (g *Generator) include(c *gin.Context) (*inclusion, map[string]string, error) {
+	param := c.Query("include")
+	if param == "" {
+		return nil, nil, nil
+	}
+
+	s, err := g.schema()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	depth := g.IncludeDepth
+	if depth <= 0 {
+		depth = 2
+	}
+
+	inc := &inclusion{}
+	paths := make(map[string][]*schema.Relationship)
+	seen := make(map[string]bool)
+	for _, path := range strings.Split(param, ",") {
+		if len(strings.Split(path, ".")) > depth {
+			return nil, map[string]string{"include": "too deep " + path}, nil
+		}
+		rels := lookupRelationships(s, path)
+		if rels == nil || !g.includable(path) {
+			return nil, map[string]string{"include": "unknown association " + path}, nil
+		}
+		paths[path] = rels
+
+		if name := jsonName(rels[0].Field); !seen[name] {
+			seen[name] = true
+			inc.names = append(inc.names, name)
+			inc.required = append(inc.required, ownFields(rels[0])...)
+		}
+	}
+
+	// filters and limits
+	conditions := make(map[string][]clause.Expression)
+	limited := make(map[string]bool)
+	for key, values := range c.Request.URL.Query() {
+		matches := includeParamRegex.FindStringSubmatch(key)
+		if matches == nil {
+			if strings.HasPrefix(key, "include[") {
+				return nil, map[string]string{key: "invalid include filter"}, nil
+			}
+			continue
+		}
+
+		path, name, op := matches[1], matches[2], matches[3]
+		rels, exists := paths[path]
+		if !exists {
+			return nil, map[string]string{key: "association not included"}, nil
+		}
+		settings := g.includeSettings(path)
+
+		if name == "limit" && op == "" {
+			limit, err := strconv.Atoi(values[0])
+			if err != nil || limit < 0 {
+				return nil, map[string]string{key: "invalid int type"}, nil
+			}
+			if settings.MaxLimit > 0 && limit > settings.MaxLimit {
+				limit = settings.MaxLimit
+			}
+			inc.limits = append(inc.limits, inclusionLimit{path: rels, limit: limit})
+			limited[path] = true
+			continue
+		}
+
+		fieldSchema := rels[len(rels)-1].FieldSchema
+		exprs, msg := filterExprs(schemaJSONFields(fieldSchema), writeOnlyFields(fieldSchema), settings.Filters, name, op, values)
+		if msg != "" {
+			return nil, map[string]string{key: msg}, nil
+		}
+		conditions[path] = append(conditions[path], exprs...)
+	}
+
+	hiddenPaths := make(map[string]bool)
+	for path, rels := range paths {
+		inc.preloads = append(inc.preloads, inclusionPreload{path: rels, conditions: conditions[path]})
+		for i := range rels {
+			// nested includes load their parents too
+			prefix := make([]string, i+1)
+			for j, rel := range rels[:i+1] {
+				prefix[j] = jsonName(rel.Field)
+			}
+			if key := strings.Join(prefix, "."); !hiddenPaths[key] {
+				hiddenPaths[key] = true
+				if names := writeOnlyFields(rels[i].FieldSchema); len(names) > 0 {
+					inc.hidden = append(inc.hidden, inclusionHidden{path: prefix, names: names})
+				}
+			}
+		}
+		if maxLimit := g.includeSettings(path).MaxLimit; maxLimit > 0 && !limited[path] {
+			inc.limits = append(inc.limits, inclusionLimit{path: rels, limit: maxLimit})
+		}
+	}
+	return inc, nil, nil
+}
+
+// Preloads the included associations into the queryset, ordered by primary key so limits are stable. When a single
+// model is fetched, limits of its direct associations are applied in SQL. Otherwise associations are loaded in full and
+// trimmed per parent after loading, so limits bound the response but not what is read from the database.
+func (inc *inclusion) preload(queryset *gorm.DB, single bool) {
+	if inc == nil {
+		return
+	}
+	for _, p := range inc.preloads {
+		conditions, fieldSchema, limit := p.conditions, p.path[len(p.path)-1].FieldSchema, -1
+		if single && len(p.path) == 1 {
+			limit = inc.limit(p.path)
+		}
+		queryset.Preload(preloadName(p.path), func(db *gorm.DB) *gorm.DB {
+			for _, expr := range conditions {
+				db = db.Where(expr)
+			}
+			for _, field := range fieldSchema.PrimaryFields {
+				db = db.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}})
+			}
+			if limit >= 0 {
+				db = db.Limit(limit)
+			}
+			return db
+		})
+	}
+}
+
+// Per parent limit of an included association path, -1 when unlimited
+func (inc *inclusion) limit(path []*schema.Relationship) int {
+	for _, l := range inc.limits {
+		if preloadName(l.path) == preloadName(path) {
+			return l.limit
+		}
+	}
+	return -1
+}
+
+// Checks if an association path is declared in Includes
+func (g *Generator) includable(path string) bool {
+	_, exists := g.Includes[path]
+	_, all := g.Includes["*"]
+	return exists || all
+}
+
+// Settings of an included association path, falling back to the "*" settings
+func (g *Generator) includeSettings(path string) Include {
+	if settings, exists := g.Includes[path]; exists {
+		return settings
+	}
+	return g.Includes["*"]
+}
+
+// Preloads ?include= associations on a listing queryset. Responds with errors and returns false when invalid.
+func (g *Generator) includeList(c *gin.Context, queryset *gorm.DB) (*inclusion, bool) {
+	inc, errs, err := g.include(c)
+	if err != nil {
+		g.abort(c, err)
+		return nil, false
+	} else if errs != nil {
+		g.abort(c, invalid(errs))
+		return nil, false
+	}
+	inc.preload(queryset, false)
+	return inc, true
+}
+
+// Preloads ?include= associations when fetching a model for a safe request. Invalid includes are left for Render to
+// report since parent models are fetched with the same query params.
+func (g *Generator) includeFetch(c *gin.Context, queryset *gorm.DB) (*inclusion, error) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return nil, nil
+	}
+
+	inc, errs, err := g.include(c)
+	if err != nil || errs != nil {
+		return nil, err
+	}
+	inc.preload(queryset, true)
+	return inc, nil
+}
+
+// Json names of the write only fields of a schema
+func writeOnlyFields(s *schema.Schema) map[string]bool {
+	names := make(map[string]bool)
+	for name, field := range schemaJSONFields(s) {
+		if restTag(field, tagWriteOnly) {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// Json names of the included associations, nil safe
+func (inc *inclusion) jsonNames() []string {
+	if inc == nil {
+		return nil
+	}
+	return inc.names
+}
+
+// Fields of the model the included associations are loaded by, nil safe
+func (inc *inclusion) requiredFields() []*schema.Field {
+	if inc == nil {
+		return nil
+	}
+	return inc.required
+}
+
+// Applies per parent limits to the loaded has many associations of a model or a list of models.
+func (inc *inclusion) trim(results interface{}) {
+	if inc == nil {
+		return
+	}
+	for _, limit := range inc.limits {
+		trimRelationship(reflect.ValueOf(results), limit.path, limit.limit)
+	}
+}
+
+func trimRelationship(value reflect.Value, path []*schema.Relationship, limit int) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			trimRelationship(value.Index(i), path, limit)
+		}
+	case reflect.Struct:
+		field, ok := fieldReflectValue(path[0].Field, value)
+		if !ok {
+			return
+		}
+		if len(path) > 1 {
+			trimRelationship(field, path[1:], limit)
+		} else if field.Kind() == reflect.Slice && field.Len() > limit {
+			field.Set(field.Slice(0, limit))
+		}
+	}
+}
+
+// Resolves a dotted json path, i.e. animals.owner, into its relationships. Returns nil when not found.
+func lookupRelationships(s *schema.Schema, path string) []*schema.Relationship {
+	var rels []*schema.Relationship
+	for _, name := range strings.Split(path, ".") {
+		var found *schema.Relationship
+		for _, rel := range s.Relationships.Relations {
+			if jsonName(rel.Field) == name {
+				found = rel
+				break
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		rels = append(rels, found)
+		s = found.FieldSchema
+	}
+	return rels
+}
+
+// Gorm preload name of relationships, i.e. Animals.Owner
+func preloadName(rels []*schema.Relationship) string {
+	names := make([]string, len(rels))
+	for i, rel := range rels {
+		names[i] = rel.Name
+	}
+	return strings.Join(names, ".")
+}
+
+// Fields of the parent model that a relationship is loaded by, so they're selected along with ?fields=
+func ownFields(rel *schema.Relationship) []*schema.Field {
+	var fields []*schema.Field
+	for _, ref := range rel.References {
+		for _, field := range []*schema.Field{ref.PrimaryKey, ref.ForeignKey} {
+			if field != nil && field.Schema == rel.Schema {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
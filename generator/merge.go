@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Options of the `rest` struct tag:
+//
+//	rest:"readonly"  clients can read but never write the field
+//	rest:"-"         the field is ignored by the generator, clients can't write it
+//	rest:"writeonly" clients can write but never read the field, i.e. a password
+const (
+	tagReadOnly  = "readonly"
+	tagIgnore    = "-"
+	tagWriteOnly = "writeonly"
+)
+
+var (
+	gormModelType     = reflect.TypeOf(gorm.Model{})
+	gormDeletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+)
+
+// Default MergerFn used by Update when none is given. Copies the writable column fields from src to dest. Write only
+// fields are kept when omitted, since clients can't send back what they never read.
+func (g *Generator) mergeFields(src interface{}, dest interface{}) error {
+	s, err := g.schema()
+	if err != nil {
+		return err
+	}
+
+	srcValue, destValue := reflect.ValueOf(src), reflect.ValueOf(dest)
+	for _, field := range s.Fields {
+		if !writable(field) {
+			continue
+		}
+		from, ok := fieldReflectValue(field, srcValue)
+		if !ok || (from.IsZero() && restTag(field, tagWriteOnly)) {
+			continue
+		}
+		if to, ok := fieldReflectValue(field, destValue); ok {
+			to.Set(from)
+		}
+	}
+	return nil
+}
+
+// Checks if clients can write a field. Primary keys, timestamps, gorm.Model fields, associations and fields hidden from
+// JSON are always protected, as are fields tagged rest:"readonly" or rest:"-".
+func writable(field *schema.Field) bool {
+	if field.DBName == "" || field.PrimaryKey || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 {
+		return false
+	}
+	if jsonName(field) == "" || restTag(field, tagReadOnly) || restTag(field, tagIgnore) {
+		return false
+	}
+	if field.FieldType == gormDeletedAtType {
+		return false
+	}
+
+	// fields of an embedded gorm.Model
+	return field.OwnerSchema == nil || field.OwnerSchema.ModelType != gormModelType
+}
+
+// Checks if a field has an option in its `rest` tag
+func restTag(field *schema.Field, option string) bool {
+	for _, value := range strings.Split(field.StructField.Tag.Get("rest"), ",") {
+		if strings.TrimSpace(value) == option {
+			return true
+		}
+	}
+	return false
+}
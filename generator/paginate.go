@@ -0,0 +1,216 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Pagination configures paging of listings with either ?offset=&limit= or ?page=&per_page= query params.
+type Pagination struct {
+	DefaultSize int  // page size when none is requested, defaults to 20
+	MaxSize     int  // largest page size a client may request, defaults to 100
+	Envelope    bool // respond with a PageResponse (or CursorPageResponse) instead of a bare JSON array
+
+	// Keyset pagination with ?cursor=&limit= instead of offsets. Results are ordered by the cursor keys and no total is
+	// counted, which keeps large tables fast and stable while rows change. Cursors are signed but readable, so write only
+	// fields and fields with a read rule can't be cursor keys.
+	Cursor       bool
+	CursorKeys   []string // json names of the unique fields ordering the cursor, defaults to the primary key
+	CursorSecret []byte   // signs cursors so clients cannot forge them, defaults to a random key per process
+}
+
+// PageResponse is the envelope of a paginated listing when Pagination.Envelope is enabled.
+type PageResponse struct {
+	Data   interface{} `json:"data"`
+	Total  int64       `json:"total"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"`
+}
+
+// A requested page of results
+type page struct {
+	offset  int
+	limit   int
+	total   int64
+	byPage  bool // requested with page/per_page rather than offset/limit
+	request *http.Request
+	cursor  *cursorPage
+}
+
+// Counts the records of a listing queryset
+type counterFn func(queryset *gorm.DB) (int64, error)
+
+// Parses the pagination query params, counts the total and limits the queryset. Responds with errors and returns
+// false when the params are invalid.
+func (g *Generator) paginate(c *gin.Context, queryset *gorm.DB, count counterFn) (*page, bool) {
+	if g.Pagination == nil {
+		return nil, true
+	}
+
+	defaultSize, maxSize := g.Pagination.DefaultSize, g.Pagination.MaxSize
+	if defaultSize <= 0 {
+		defaultSize = 20
+	}
+	if maxSize <= 0 {
+		maxSize = 100
+	}
+
+	p := &page{request: c.Request}
+	errs := make(map[string]string)
+	query := c.Request.URL.Query()
+	number := 1
+	if g.Pagination.Cursor {
+		p.limit = queryInt(query, "limit", defaultSize, 1, errs)
+	} else if query.Has("offset") || query.Has("limit") {
+		p.offset = queryInt(query, "offset", 0, 0, errs)
+		p.limit = queryInt(query, "limit", defaultSize, 1, errs)
+	} else {
+		p.byPage = true
+		number = queryInt(query, "page", 1, 1, errs)
+		p.limit = queryInt(query, "per_page", defaultSize, 1, errs)
+	}
+	if len(errs) > 0 {
+		g.abort(c, invalid(errs))
+		return nil, false
+	}
+
+	if p.limit > maxSize {
+		p.limit = maxSize
+	}
+	if p.byPage {
+		p.offset = (number - 1) * p.limit
+	}
+
+	if g.Pagination.Cursor {
+		var err error
+		if p.cursor, err = g.paginateCursor(c, queryset, p.limit); errors.Is(err, errInvalidCursor) {
+			g.abort(c, invalid(map[string]string{"cursor": err.Error()}))
+			return nil, false
+		} else if err != nil {
+			g.abort(c, err)
+			return nil, false
+		}
+		return p, true
+	}
+
+	// count before limiting so the total covers every page
+	total, err := count(queryset.Session(&gorm.Session{}))
+	if err != nil {
+		g.abort(c, err)
+		return nil, false
+	}
+	p.total = total
+
+	// offsets only page reliably over a stable order, which databases don't guarantee without ORDER BY
+	if _, ordered := queryset.Statement.Clauses["ORDER BY"]; !ordered {
+		s, err := g.schema()
+		if err != nil {
+			g.abort(c, err)
+			return nil, false
+		}
+		orderByPrimaryKey(queryset, s, nil)
+	}
+	queryset.Offset(p.offset).Limit(p.limit)
+	return p, true
+}
+
+// Responds with a listing, along with the pagination headers or envelope when paginated, and its cache validators.
+func (g *Generator) renderList(c *gin.Context, results interface{}, p *page, fields *fieldset) {
+	if p != nil && p.cursor != nil {
+		p.cursor.finish(results)
+	}
+
+	list, err := fields.project(results)
+	if err != nil {
+		g.abort(c, err)
+		return
+	}
+
+	if p == nil {
+		g.respondList(c, list, results)
+		return
+	}
+
+	if p.cursor != nil {
+		c.Header("X-Next-Cursor", p.cursor.next)
+		c.Header("X-Prev-Cursor", p.cursor.prev)
+		if links := p.cursor.links(); links != "" {
+			c.Header("Link", links)
+		}
+		if g.Pagination.Envelope {
+			g.respondList(c, CursorPageResponse{Data: list, NextCursor: p.cursor.next, PrevCursor: p.cursor.prev, Limit: p.limit}, results)
+		} else {
+			g.respondList(c, list, results)
+		}
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(p.total, 10))
+	c.Header("Link", p.links())
+	if g.Pagination.Envelope {
+		g.respondList(c, PageResponse{Data: list, Total: p.total, Offset: p.offset, Limit: p.limit}, results)
+	} else {
+		g.respondList(c, list, results)
+	}
+}
+
+// Builds an RFC 8288 Link header value with first, prev, next and last relations.
+func (p *page) links() string {
+	last := 0
+	if p.total > 0 {
+		last = int((p.total - 1) / int64(p.limit) * int64(p.limit))
+	}
+
+	links := []string{p.link(0, "first")}
+	if p.offset > 0 {
+		prev := p.offset - p.limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, p.link(prev, "prev"))
+	}
+	if int64(p.offset+p.limit) < p.total {
+		links = append(links, p.link(p.offset+p.limit, "next"))
+	}
+	links = append(links, p.link(last, "last"))
+
+	return strings.Join(links, ", ")
+}
+
+// Builds a link to the page at the offset, keeping the style of the request params.
+func (p *page) link(offset int, rel string) string {
+	query := p.request.URL.Query()
+	if p.byPage {
+		query.Set("page", strconv.Itoa(offset/p.limit+1))
+		query.Set("per_page", strconv.Itoa(p.limit))
+	} else {
+		query.Set("offset", strconv.Itoa(offset))
+		query.Set("limit", strconv.Itoa(p.limit))
+	}
+
+	u := url.URL{Path: p.request.URL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel)
+}
+
+// Parses an integer query param, recording a validation error when it is invalid or below min.
+func queryInt(query url.Values, name string, fallback int, min int, errs map[string]string) int {
+	if !query.Has(name) {
+		return fallback
+	}
+	value, err := strconv.Atoi(query.Get(name))
+	if err != nil {
+		errs[name] = "invalid int type"
+		return fallback
+	} else if value < min {
+		errs[name] = fmt.Sprintf("min %d", min)
+		return fallback
+	}
+	return value
+}
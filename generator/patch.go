@@ -0,0 +1,326 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Applies a patch document to the decoded JSON representation of a model
+type patchFn func(doc interface{}, patch []byte) (interface{}, error)
+
+// Creates a handler that partially updates a single record, then stores it into the context.
+//
+// A plain application/json body applies the keys present in the body: a key set to a zero value is applied while an
+// omitted key is left untouched, and only the present fields are validated. RFC 6902 application/json-patch+json and
+// RFC 7396 application/merge-patch+json documents are applied to the JSON representation of the record and the result
+// is validated as a whole. A failing JSON Patch test operation responds with 409 Conflict.
+//
+// Only changed columns are saved.
+func (g *Generator) Patch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ok := g.checkPreconditions(c, c.MustGet(g.Param)); !ok {
+			return
+		}
+
+		switch c.ContentType() {
+		case MIMEJSONPatch:
+			g.patchDocument(c, applyJSONPatch)
+		case MIMEMergePatch:
+			g.patchDocument(c, applyMergePatch)
+		default:
+			g.patchJSON(c)
+		}
+	}
+}
+
+// Applies the keys present in a JSON body
+func (g *Generator) patchJSON(c *gin.Context) {
+	dest := c.MustGet(g.Param)
+
+	body := map[string]json.RawMessage{}
+	if err := bindJSON(c, &body); err != nil {
+		g.abort(c, g.validationError(c, err))
+		return
+	}
+
+	fields, err := g.jsonFields()
+	if err != nil {
+		g.abort(c, err)
+		return
+	}
+
+	// Apply the present keys onto a copy, so the context model is untouched when invalid
+	inst := g.copy(dest)
+	if err := g.applyKeys(c, fields, inst, body); err != nil {
+		g.abort(c, err)
+		return
+	}
+
+	g.savePatch(c, dest, inst)
+}
+
+// Applies the keys of a JSON object to a model, then validates the present fields. Returns validation errors keyed by
+// json name.
+func (g *Generator) applyKeys(c *gin.Context, fields map[string]*schema.Field, inst interface{}, body map[string]json.RawMessage) *Error {
+	errs := make(map[string]string)
+	var present []*schema.Field
+	for key, raw := range body {
+		if field, msg := setJSONField(fields, inst, key, raw); msg != "" {
+			errs[key] = msg
+		} else {
+			present = append(present, field)
+		}
+	}
+	if len(errs) > 0 {
+		return invalid(errs)
+	} else if err := validatePartial(inst, present); err != nil {
+		return g.validationError(c, err)
+	}
+	return nil
+}
+
+// Applies a patch document to the JSON representation of the record
+func (g *Generator) patchDocument(c *gin.Context, apply patchFn) {
+	dest := c.MustGet(g.Param)
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		g.abort(c, newError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	fields, err := g.jsonFields()
+	if err != nil {
+		g.abort(c, err)
+		return
+	}
+	original, err := modelDocument(dest, fields)
+	if err != nil {
+		g.abort(c, err)
+		return
+	}
+	working, _ := modelDocument(dest, fields)
+	hidden, err := g.hiddenFields(c)
+	if err != nil {
+		g.abort(c, err)
+		return
+	}
+	for name := range hidden {
+		delete(original, name)
+		delete(working, name)
+	}
+
+	patched, err := apply(working, patch)
+	if errors.Is(err, ErrPatchTest) {
+		g.abort(c, newError(http.StatusConflict, err.Error()))
+		return
+	} else if err != nil {
+		g.abort(c, newError(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+	result, ok := patched.(map[string]interface{})
+	if !ok {
+		g.abort(c, newError(http.StatusUnprocessableEntity, "patch must result in an object"))
+		return
+	}
+
+	// Apply the changed keys onto a copy, so the context model is untouched when invalid
+	inst := g.copy(dest)
+	errs := make(map[string]string)
+	var changed []*schema.Field
+	for key := range mergeKeys(original, result) {
+		value, exists := result[key]
+		if exists && jsonEqual(original[key], value) {
+			continue
+		}
+
+		var raw json.RawMessage // removed keys reset the field to its zero value
+		if exists {
+			raw, _ = json.Marshal(value)
+		}
+		if field, msg := setJSONField(fields, inst, key, raw); msg != "" {
+			errs[key] = msg
+		} else {
+			changed = append(changed, field)
+		}
+	}
+	if len(errs) > 0 {
+		g.abort(c, invalid(errs))
+		return
+	} else if err := validateStruct(inst); err != nil {
+		g.abort(c, g.validationError(c, err))
+		return
+	}
+
+	g.savePatch(c, dest, inst)
+}
+
+// Saves the changed columns of the patched model in a transaction and stores it into the context.
+func (g *Generator) savePatch(c *gin.Context, dest interface{}, inst interface{}) {
+	scope, ok := g.scope(c)
+	if !ok {
+		return
+	}
+	lock, err := g.versionLock(dest)
+	if err == nil {
+		var errs map[string]string
+		if errs, err = g.guardWrites(c, inst, dest, fieldChanged(inst, dest)); errs != nil {
+			g.abortGuardWrites(c, errs, nil)
+			return
+		}
+	}
+	if err == nil {
+		err = scope.stamp(inst)
+	}
+	if err == nil && g.Policy != nil && !g.Policy.CanUpdate(c, dest, inst) {
+		g.abortForbidden(c, true)
+		return
+	}
+	if err == nil {
+		err = g.db(c).Transaction(func(tx *gorm.DB) error {
+			return g.saveChanges(c, tx, scope, lock, dest, inst)
+		})
+	}
+	if err != nil {
+		g.abort(c, err)
+		return
+	}
+
+	c.Set(g.Param, inst)
+}
+
+// Runs the update hooks and saves the columns that differ from the original model. Columns changed by the BeforeUpdate
+// hook are saved too.
+func (g *Generator) saveChanges(c *gin.Context, tx *gorm.DB, scope scopeValues, lock *versionLock, dest interface{}, inst interface{}) error {
+	s, err := g.schema()
+	if err != nil {
+		return err
+	}
+	if err := g.BeforeUpdate.run(c, tx, dest, inst); err != nil {
+		return err
+	}
+
+	columns := make(map[string]interface{})
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.PrimaryKey {
+			continue
+		}
+		if value := fieldValue(field, reflect.ValueOf(inst)); !reflect.DeepEqual(value, fieldValue(field, reflect.ValueOf(dest))) {
+			columns[field.DBName] = value
+		}
+	}
+
+	if len(columns) > 0 {
+		queryset := scope.where(tx.Model(inst))
+		if lock != nil {
+			if err := lock.next(inst); err != nil {
+				return err
+			}
+			columns[lock.field.DBName] = fieldValue(lock.field, reflect.ValueOf(inst))
+			queryset = queryset.Where(lock.condition())
+		}
+		result := queryset.Updates(columns)
+		if result.Error == nil && lock != nil && result.RowsAffected == 0 {
+			return ErrPreconditionFailed
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+	return g.AfterUpdate.run(c, tx, dest, inst)
+}
+
+// Creates a shallow copy of a model
+func (g *Generator) copy(model interface{}) interface{} {
+	inst := g.new()
+	reflect.ValueOf(inst).Elem().Set(reflect.ValueOf(model).Elem())
+	return inst
+}
+
+// Decodes a JSON value into a column field of the model. A nil value resets the field. Returns a validation message when
+// the key is unknown, read only or of the wrong type.
+func setJSONField(fields map[string]*schema.Field, model interface{}, key string, raw json.RawMessage) (*schema.Field, string) {
+	field, exists := fields[key]
+	if !exists {
+		return nil, "unknown field"
+	} else if !writable(field) {
+		return nil, "read only"
+	}
+
+	value := reflect.New(field.FieldType)
+	if raw != nil {
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return nil, "invalid " + field.FieldType.String() + " type"
+		}
+	}
+
+	target, ok := fieldReflectValue(field, reflect.ValueOf(model))
+	if !ok {
+		return nil, "read only"
+	}
+	target.Set(value.Elem())
+	return field, ""
+}
+
+// Decodes the JSON representation of a model, including zero valued columns omitted by the json tags
+func modelDocument(model interface{}, fields map[string]*schema.Field) (map[string]interface{}, error) {
+	raw, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decodeJSON(raw)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := decoded.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("model is not a JSON object")
+	}
+
+	for name, field := range fields {
+		if _, exists := doc[name]; exists {
+			continue
+		}
+		raw, err := json.Marshal(fieldValue(field, reflect.ValueOf(model)))
+		if err != nil {
+			return nil, err
+		}
+		if doc[name], err = decodeJSON(raw); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// Union of the keys of both documents
+func mergeKeys(a map[string]interface{}, b map[string]interface{}) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+	return keys
+}
+
+// Runs the validator against the given fields of the model only
+func validatePartial(model interface{}, fields []*schema.Field) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = strings.Join(field.BindNames, ".")
+	}
+	return validate.StructPartial(model, names...)
+}
@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/schema"
+)
+
+// RolesKey is the context key of the roles of a request, a string or a []string, checked by Roles.
+const RolesKey = "roles"
+
+// Permission allows a request to access a field
+type Permission func(c *gin.Context) bool
+
+// FieldRule restricts who can read or write a field. A nil permission allows every request.
+type FieldRule struct {
+	Read  Permission
+	Write Permission
+}
+
+// Roles allows requests having any of the roles, see RolesKey.
+func Roles(roles ...string) Permission {
+	return func(c *gin.Context) bool {
+		var granted []string
+		switch value := c.Value(RolesKey).(type) {
+		case string:
+			granted = []string{value}
+		case []string:
+			granted = value
+		}
+		for _, role := range granted {
+			for _, allowed := range roles {
+				if role == allowed {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// Finds the json names of the fields left out of the responses of a request: fields tagged rest:"writeonly" and fields
+// the request can't read.
+func (g *Generator) hiddenFields(c *gin.Context) (map[string]bool, error) {
+	fields, err := g.jsonFields()
+	if err != nil {
+		return nil, err
+	}
+
+	hidden := make(map[string]bool)
+	for name, field := range fields {
+		if restTag(field, tagWriteOnly) {
+			hidden[name] = true
+		}
+	}
+	for name, rule := range g.FieldRules {
+		if _, exists := fields[name]; !exists {
+			return nil, fmt.Errorf("unknown field rule %s", name)
+		}
+		if rule.Read != nil && !rule.Read(c) {
+			hidden[name] = true
+		}
+	}
+	return hidden, nil
+}
+
+// Guards the fields a request can't write by restoring them from the original model, or zeroing them when there is
+// none. Written fields are returned as validation errors instead when RejectForbiddenFields is set.
+func (g *Generator) guardWrites(c *gin.Context, dest interface{}, original interface{}, written func(field *schema.Field) bool) (map[string]string, error) {
+	if len(g.FieldRules) == 0 {
+		return nil, nil
+	}
+	fields, err := g.jsonFields()
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make(map[string]string)
+	for name, rule := range g.FieldRules {
+		field, exists := fields[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown field rule %s", name)
+		}
+		if rule.Write == nil || rule.Write(c) {
+			continue
+		}
+
+		if g.RejectForbiddenFields && written(field) {
+			errs[name] = "forbidden"
+			continue
+		}
+
+		target, ok := fieldReflectValue(field, reflect.ValueOf(dest))
+		if !ok {
+			continue
+		}
+		if original == nil {
+			target.Set(reflect.Zero(target.Type()))
+		} else if value, ok := fieldReflectValue(field, reflect.ValueOf(original)); ok {
+			target.Set(value)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs, nil
+	}
+	return nil, nil
+}
+
+// Responds to the result of guardWrites and returns false when the request is rejected
+func (g *Generator) abortGuardWrites(c *gin.Context, errs map[string]string, err error) bool {
+	if err != nil {
+		g.abort(c, err)
+		return false
+	} else if errs != nil {
+		g.abort(c, &Error{Status: http.StatusUnprocessableEntity, Message: "validation errors", Errors: errs})
+		return false
+	}
+	return true
+}
+
+// Checks if a field of the model has a non-zero value
+func fieldNonZero(model interface{}) func(field *schema.Field) bool {
+	return func(field *schema.Field) bool {
+		value, ok := fieldReflectValue(field, reflect.ValueOf(model))
+		return ok && !value.IsZero()
+	}
+}
+
+// Checks if a field differs between two models
+func fieldChanged(a interface{}, b interface{}) func(field *schema.Field) bool {
+	return func(field *schema.Field) bool {
+		return !reflect.DeepEqual(fieldValue(field, reflect.ValueOf(a)), fieldValue(field, reflect.ValueOf(b)))
+	}
+}
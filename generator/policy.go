@@ -0,0 +1,32 @@
+package generator
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ErrForbidden is the message of requests denied by a Policy.
+var ErrForbidden = errors.New("forbidden")
+
+// Policy authorizes the actions of the handlers. Records are checked after they are loaded, and created or updated
+// records after the input is merged, before anything is saved.
+type Policy interface {
+	CanList(c *gin.Context) bool
+	CanRead(c *gin.Context, model interface{}) bool
+	CanCreate(c *gin.Context, model interface{}) bool
+	CanUpdate(c *gin.Context, old interface{}, model interface{}) bool
+	CanDelete(c *gin.Context, model interface{}) bool
+}
+
+// Aborts a request denied by the policy with 403 Forbidden. Denied records respond with 404 Not Found instead when
+// HideForbidden is set, so they can't be told apart from missing ones.
+func (g *Generator) abortForbidden(c *gin.Context, record bool) {
+	if record && g.HideForbidden {
+		g.abort(c, gorm.ErrRecordNotFound)
+	} else {
+		g.abort(c, newError(http.StatusForbidden, ErrForbidden.Error()))
+	}
+}
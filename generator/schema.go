@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Cache of the schemas parsed before a generator without DB resolved one
+var defaultSchemas sync.Map
+
+// Parses the model schema. Gorm caches parsed schemas so this is cheap to call per request. Generators without DB use
+// the config of the database their DBResolver returned, or gorm's default naming before any was resolved.
+func (g *Generator) schema() (*schema.Schema, error) {
+	db := g.DB
+	if db == nil {
+		config, ok := g.resolved.Load().(*gorm.Config)
+		if !ok {
+			return schema.Parse(g.new(), &defaultSchemas, schema.NamingStrategy{})
+		}
+		db = &gorm.DB{Config: config}
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(g.new()); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// Maps the json field names of the model to their database fields. Fields without a column are left out.
+func (g *Generator) jsonFields() (map[string]*schema.Field, error) {
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	return schemaJSONFields(s), nil
+}
+
+// Maps the json field names of a schema to their database fields.
+func schemaJSONFields(s *schema.Schema) map[string]*schema.Field {
+	fields := make(map[string]*schema.Field)
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		if name := jsonName(field); name != "" {
+			fields[name] = field
+		}
+	}
+	return fields
+}
+
+// Retrieves the json name of a field, empty if the field is not serialized.
+func jsonName(field *schema.Field) string {
+	name := strings.Split(field.StructField.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	} else if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// Checks if name is in the list of allowed names. "*" allows everything.
+func allowed(list []string, name string) bool {
+	for _, item := range list {
+		if item == "*" || item == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Retrieves the value of a field from a model struct, nil when it is inside a nil embedded pointer.
+func fieldValue(field *schema.Field, model reflect.Value) interface{} {
+	if value, ok := fieldReflectValue(field, model); ok {
+		return value.Interface()
+	}
+	return nil
+}
+
+// Retrieves the reflected field from a model struct, false when it is inside a nil embedded pointer.
+func fieldReflectValue(field *schema.Field, model reflect.Value) (reflect.Value, bool) {
+	for model.Kind() == reflect.Ptr {
+		model = model.Elem()
+	}
+	for _, index := range field.StructField.Index {
+		if index >= 0 {
+			model = model.Field(index)
+		} else if model = model.Field(-index - 1); model.IsNil() {
+			return reflect.Value{}, false
+		} else {
+			model = model.Elem()
+		}
+	}
+	return model, true
+}
+
+// Finds the single primary key field of the model, which bulk and upsert requests identify records by
+func (g *Generator) primaryField() (*schema.Field, error) {
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	if len(s.PrimaryFields) != 1 || jsonName(s.PrimaryFields[0]) == "" {
+		return nil, errors.New("model needs a single serialized primary key")
+	}
+	return s.PrimaryFields[0], nil
+}
@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ScopeFn returns the column values a request is restricted to, keyed by json field name, i.e. {"tenant_id": 42}.
+// Returning an error aborts the request, use an *Error to choose the response.
+type ScopeFn func(c *gin.Context) (map[string]interface{}, error)
+
+// Column values of a request scope
+type scopeValues map[*schema.Field]interface{}
+
+// Resolves the scope of a request. Responds with the error and returns false when it fails.
+func (g *Generator) scope(c *gin.Context) (scopeValues, bool) {
+	if g.Scope == nil {
+		return nil, true
+	}
+
+	values, err := g.Scope(c)
+	if err != nil {
+		g.abort(c, err)
+		return nil, false
+	}
+
+	fields, err := g.jsonFields()
+	if err != nil {
+		g.abort(c, err)
+		return nil, false
+	}
+	scope := make(scopeValues, len(values))
+	for name, value := range values {
+		field, exists := fields[name]
+		if !exists {
+			g.abort(c, fmt.Errorf("unknown scope field %s", name))
+			return nil, false
+		}
+		// values often come from headers or claims as strings
+		if s, ok := value.(string); ok {
+			if value, err = parseFieldValue(field, s); err != nil {
+				g.abort(c, fmt.Errorf("invalid scope value for %s: %w", name, err))
+				return nil, false
+			}
+		}
+		scope[field] = value
+	}
+	return scope, true
+}
+
+// Restricts the queryset to the rows of the scope
+func (scope scopeValues) where(queryset *gorm.DB) *gorm.DB {
+	for field, value := range scope {
+		queryset = queryset.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value})
+	}
+	return queryset
+}
+
+// Sets the scope values on a model, overriding any input
+func (scope scopeValues) stamp(model interface{}) error {
+	for field, value := range scope {
+		target, ok := fieldReflectValue(field, reflect.ValueOf(model))
+		if !ok {
+			return fmt.Errorf("unwritable scope field %s", field.Name)
+		}
+		v := reflect.ValueOf(value)
+		if !v.IsValid() || !v.Type().ConvertibleTo(target.Type()) {
+			return fmt.Errorf("invalid scope value for %s", field.Name)
+		}
+		target.Set(v.Convert(target.Type()))
+	}
+	return nil
+}
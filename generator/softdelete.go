@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Finds the gorm.DeletedAt field of a soft deleted model, nil when records are deleted for good.
+func (g *Generator) deletedAtField() (*schema.Field, error) {
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range s.Fields {
+		if field.FieldType == gormDeletedAtType && field.DBName != "" {
+			return field, nil
+		}
+	}
+	return nil, nil
+}
+
+// Checks if a model is soft deleted, given its gorm.DeletedAt field
+func softDeleted(field *schema.Field, model interface{}) bool {
+	if field == nil {
+		return false
+	}
+	deletedAt, ok := fieldValue(field, reflect.ValueOf(model)).(gorm.DeletedAt)
+	return ok && deletedAt.Valid
+}
+
+// Applies ?trashed=with to list soft deleted records along with the others, or ?trashed=only to list only them.
+func (g *Generator) trashed(c *gin.Context, queryset *gorm.DB) (map[string]string, error) {
+	trashed, exists := c.GetQuery("trashed")
+	if !exists {
+		return nil, nil
+	}
+
+	field, err := g.deletedAtField()
+	if err != nil {
+		return nil, err
+	} else if field == nil {
+		return map[string]string{"trashed": "unsupported"}, nil
+	}
+
+	switch trashed {
+	case "with":
+		queryset.Unscoped()
+	case "only":
+		queryset.Unscoped().Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: nil})
+	default:
+		return map[string]string{"trashed": "must be only or with"}, nil
+	}
+	return nil, nil
+}
+
+// Checks if a request purges records with DELETE ?force=true, which the ForceDelete permission must allow.
+func (g *Generator) purging(c *gin.Context) bool {
+	return forceRequested(c) && g.ForceDelete != nil && g.ForceDelete(c)
+}
+
+func forceRequested(c *gin.Context) bool {
+	force, _ := strconv.ParseBool(c.Query("force"))
+	return force && c.Request.Method == http.MethodDelete
+}
+
+// Creates a handler that restores a soft deleted model and stores it into the context. Restoring runs the update
+// policy and hooks.
+func (g *Generator) Restore() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		field, err := g.deletedAtField()
+		if err != nil {
+			g.abort(c, err)
+			return
+		} else if field == nil || c.Param(g.Param) == "" {
+			g.abort(c, gorm.ErrRecordNotFound)
+			return
+		}
+		scope, ok := g.scope(c)
+		if !ok {
+			return
+		}
+
+		inst := g.new()
+		deleted := clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: nil}
+		if err := scope.where(g.db(c).Unscoped().Model(inst)).Where(deleted).Take(inst, c.Param(g.Param)).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			g.abort(c, gorm.ErrRecordNotFound)
+			return
+		} else if err != nil {
+			g.abort(c, err)
+			return
+		}
+
+		old := g.copy(inst)
+		if value, ok := fieldReflectValue(field, reflect.ValueOf(inst)); ok {
+			value.Set(reflect.Zero(value.Type()))
+		}
+		if g.Policy != nil && !g.Policy.CanUpdate(c, old, inst) {
+			g.abortForbidden(c, true)
+			return
+		}
+
+		err = g.db(c).Transaction(func(tx *gorm.DB) error {
+			if err := g.BeforeUpdate.run(c, tx, old, inst); err != nil {
+				return err
+			}
+			if err := scope.where(tx.Unscoped().Model(inst)).Update(field.DBName, nil).Error; err != nil {
+				return err
+			}
+			return g.AfterUpdate.run(c, tx, old, inst)
+		})
+		if err != nil {
+			g.abort(c, err)
+			return
+		}
+
+		c.Set(g.Param, inst)
+	}
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Orders the queryset by the ?sort= query param, i.e. ?sort=-age,name sorts by age descending then name. The primary
+// key is always appended as a tie-breaker so pages are stable. Fields hidden from the request are unsortable, the order
+// would reveal their values. Returns validation errors keyed by query param.
+func (g *Generator) sort(c *gin.Context, queryset *gorm.DB) (map[string]string, error) {
+	param := c.Query("sort")
+	if param == "" {
+		return nil, nil
+	}
+	if g.Pagination != nil && g.Pagination.Cursor {
+		return map[string]string{"sort": "not supported with cursor pagination"}, nil
+	}
+
+	s, err := g.schema()
+	if err != nil {
+		return nil, err
+	}
+	fields, err := g.jsonFields()
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := g.hiddenFields(c)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make(map[string]bool)
+	for _, name := range strings.Split(param, ",") {
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		field, exists := fields[name]
+		if !exists || hidden[name] || !g.sortable(name, field) {
+			return map[string]string{"sort": "unsortable field " + name}, nil
+		}
+
+		queryset.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Desc: desc})
+		sorted[field.DBName] = true
+	}
+
+	orderByPrimaryKey(queryset, s, sorted)
+	return nil, nil
+}
+
+// Orders the queryset by the primary key fields that weren't sorted by yet, keyed by column
+func orderByPrimaryKey(queryset *gorm.DB, s *schema.Schema, sorted map[string]bool) {
+	for _, field := range s.PrimaryFields {
+		if !sorted[field.DBName] {
+			queryset.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}})
+		}
+	}
+}
+
+// Checks if a field can be sorted. Defaults to indexed fields when Sorts is empty.
+func (g *Generator) sortable(name string, field *schema.Field) bool {
+	if len(g.Sorts) > 0 {
+		return allowed(g.Sorts, name)
+	}
+
+	_, index := field.TagSettings["INDEX"]
+	_, uniqueIndex := field.TagSettings["UNIQUEINDEX"]
+	return field.PrimaryKey || field.Unique || index || uniqueIndex
+}
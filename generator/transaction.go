@@ -0,0 +1,140 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TxKey is the context key of the request transaction opened by the Transaction middleware.
+const TxKey = "generator.tx"
+
+// Context key of the callbacks run when the request transaction ends
+const txCallbacksKey = "generator.txCallbacks"
+
+// Rolls back a request transaction that was already answered
+var errRollback = errors.New("rollback")
+
+// Transaction creates a middleware that runs the rest of the handler chain in a database transaction. Generator
+// handlers use it instead of their DB. It commits when the response is 2xx, and rolls back when the chain aborts,
+// responds with another status or panics.
+//
+// The response is buffered until the transaction ends, so a failed commit is answered with a 500 instead of the
+// response of the handlers. Streamed responses are only sent once the chain returns.
+func Transaction(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := c.Writer
+		buffer := &bufferedWriter{ResponseWriter: writer, header: writer.Header().Clone(), status: http.StatusOK}
+		c.Writer = buffer
+		defer func() { c.Writer = writer }()
+
+		var callbacks []func(err error)
+		err := db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+			c.Set(TxKey, tx)
+			c.Set(txCallbacksKey, &callbacks)
+			c.Next()
+			if c.IsAborted() || c.Writer.Status() < http.StatusOK || c.Writer.Status() >= http.StatusMultipleChoices {
+				return errRollback
+			}
+			return nil
+		})
+		c.Writer = writer
+		if errors.Is(err, errRollback) {
+			err = nil
+		}
+		for _, callback := range callbacks {
+			callback(err)
+		}
+		if err != nil {
+			DefaultErrorRenderer(c, asError(err))
+			c.Abort()
+			return
+		}
+		buffer.flush()
+	}
+}
+
+// Runs fn once the request transaction of the Transaction middleware was committed or rolled back, with the error of a
+// failed commit. Runs fn right away without a request transaction.
+func afterTransaction(c *gin.Context, fn func(err error)) {
+	if callbacks, ok := c.Get(txCallbacksKey); ok {
+		if callbacks, ok := callbacks.(*[]func(err error)); ok {
+			*callbacks = append(*callbacks, fn)
+			return
+		}
+	}
+	fn(nil)
+}
+
+// Holds a response until it is flushed
+type bufferedWriter struct {
+	gin.ResponseWriter
+	header  http.Header
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+// Buffered responses can't be flushed early
+func (w *bufferedWriter) Flush() {}
+
+// Sends the buffered response to the underlying writer
+func (w *bufferedWriter) flush() {
+	header := w.ResponseWriter.Header()
+	for name := range header {
+		if _, exists := w.header[name]; !exists {
+			header.Del(name)
+		}
+	}
+	for name, values := range w.header {
+		header[name] = values
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.written {
+		w.ResponseWriter.WriteHeaderNow()
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
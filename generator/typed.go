@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MergerFnOf is the type-safe version of MergerFn.
+type MergerFnOf[T any] func(src *T, dest *T) error
+
+// GeneratorOf is a type-safe Generator. Handlers work with *T and []T directly instead of going through reflection,
+// and merge functions receive *T so there is no need for type assertions.
+type GeneratorOf[T any] struct {
+	*Generator
+}
+
+// NewOf creates a type-safe generator for model T.
+func NewOf[T any](db *gorm.DB, paramName string) *GeneratorOf[T] {
+	mt := reflect.TypeOf((*T)(nil)).Elem()
+	return &GeneratorOf[T]{&Generator{
+		DB:         db,
+		model:      mt,
+		models:     reflect.SliceOf(mt),
+		Param:      paramName,
+		newFn:      func() interface{} { return new(T) },
+		newSliceFn: func() interface{} { return &[]T{} },
+	}}
+}
+
+// Get retrieves the model stored in the context by Fetch, Create or Update.
+func (g *GeneratorOf[T]) Get(c *gin.Context) (model *T, ok bool) {
+	value, exists := c.Get(g.Param)
+	if !exists {
+		return nil, false
+	}
+	model, ok = value.(*T)
+	return
+}
+
+// MustGet retrieves the model stored in the context, panics if it does not exist.
+func (g *GeneratorOf[T]) MustGet(c *gin.Context) *T {
+	return c.MustGet(g.Param).(*T)
+}
+
+// Creates a handler that updates a single record and stores it into the context.
+func (g *GeneratorOf[T]) Update(mergeFn MergerFnOf[T]) gin.HandlerFunc {
+	return g.Generator.Update(g.merger(mergeFn))
+}
+
+// Handy function to create boilerplate handlers for CRUD operations.
+func (g *GeneratorOf[T]) Handlers(resolvers ResolverFn, mergeFn MergerFnOf[T]) *Handlers {
+	return g.Generator.Handlers(resolvers, g.merger(mergeFn))
+}
+
+// Handy function to create boilerplate handlers for CRUD operations with associations.
+func (g *GeneratorOf[T]) AssociatedHandlers(assoc Association, resolvers ResolverFn, mergeFn MergerFnOf[T]) *Handlers {
+	return g.Generator.AssociatedHandlers(assoc, resolvers, g.merger(mergeFn))
+}
+
+// Adapts a typed merge function to a MergerFn
+func (g *GeneratorOf[T]) merger(mergeFn MergerFnOf[T]) MergerFn {
+	if mergeFn == nil {
+		return nil
+	}
+	return func(src, dest interface{}) error {
+		return mergeFn(src.(*T), dest.(*T))
+	}
+}
+
+// Sets the hook run before a record is created.
+func (g *GeneratorOf[T]) OnBeforeCreate(fn func(c *gin.Context, tx *gorm.DB, model *T) error) {
+	g.BeforeCreate = hookOf(fn)
+}
+
+// Sets the hook run after a record is created.
+func (g *GeneratorOf[T]) OnAfterCreate(fn func(c *gin.Context, tx *gorm.DB, model *T) error) {
+	g.AfterCreate = hookOf(fn)
+}
+
+// Sets the hook run before a record is updated, with a snapshot of the record before the change.
+func (g *GeneratorOf[T]) OnBeforeUpdate(fn func(c *gin.Context, tx *gorm.DB, old *T, model *T) error) {
+	g.BeforeUpdate = updateHookOf(fn)
+}
+
+// Sets the hook run after a record is updated, with a snapshot of the record before the change.
+func (g *GeneratorOf[T]) OnAfterUpdate(fn func(c *gin.Context, tx *gorm.DB, old *T, model *T) error) {
+	g.AfterUpdate = updateHookOf(fn)
+}
+
+// Sets the hook run before a record is deleted.
+func (g *GeneratorOf[T]) OnBeforeDelete(fn func(c *gin.Context, tx *gorm.DB, model *T) error) {
+	g.BeforeDelete = hookOf(fn)
+}
+
+// Sets the hook run after a record is deleted.
+func (g *GeneratorOf[T]) OnAfterDelete(fn func(c *gin.Context, tx *gorm.DB, model *T) error) {
+	g.AfterDelete = hookOf(fn)
+}
+
+// Sets the hook run after a record is fetched.
+func (g *GeneratorOf[T]) OnAfterFetch(fn func(c *gin.Context, tx *gorm.DB, model *T) error) {
+	g.AfterFetch = hookOf(fn)
+}
+
+// Adapts a typed hook to a HookFn, nil clears the hook
+func hookOf[T any](fn func(c *gin.Context, tx *gorm.DB, model *T) error) HookFn {
+	if fn == nil {
+		return nil
+	}
+	return func(c *gin.Context, tx *gorm.DB, model interface{}) error {
+		return fn(c, tx, model.(*T))
+	}
+}
+
+// Adapts a typed update hook to an UpdateHookFn, nil clears the hook
+func updateHookOf[T any](fn func(c *gin.Context, tx *gorm.DB, old *T, model *T) error) UpdateHookFn {
+	if fn == nil {
+		return nil
+	}
+	return func(c *gin.Context, tx *gorm.DB, old interface{}, model interface{}) error {
+		return fn(c, tx, old.(*T), model.(*T))
+	}
+}
@@ -0,0 +1,151 @@
+package generator
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Checks if Fetch lets a PUT request through to create a missing record, see Generator.Upsert
+func (g *Generator) upserting(c *gin.Context) bool {
+	return g.Upsert && c.Request.Method == http.MethodPut
+}
+
+// Creates the record of a PUT request with the key of the path, runs the create policy and hooks, then stores it into
+// the context. The insert is an upsert on the primary key, so a record created concurrently within the scope is replaced
+// and responds with 200 OK. Records outside of the scope are never overwritten and respond with 409 Conflict, as do soft
+// deleted records, which have to be restored first.
+func (g *Generator) createWithKey(c *gin.Context) {
+	if c.GetHeader("If-Match") != "" {
+		g.abort(c, ErrPreconditionFailed)
+		return
+	}
+	pk, err := g.primaryField()
+	if err != nil {
+		g.abort(c, err)
+		return
+	}
+	id, err := parseFieldValue(pk, c.Param(g.Param))
+	if err != nil {
+		g.abort(c, gorm.ErrRecordNotFound)
+		return
+	}
+
+	inst := g.new()
+	if err := g.bindAndValidate(c, inst); err != nil {
+		g.abort(c, err)
+		return
+	}
+	target, ok := fieldReflectValue(pk, reflect.ValueOf(inst))
+	if !ok || !reflect.TypeOf(id).ConvertibleTo(target.Type()) {
+		g.abort(c, newError(http.StatusInternalServerError, "unwritable primary key "+pk.Name))
+		return
+	}
+	key := reflect.ValueOf(id).Convert(target.Type())
+	if !target.IsZero() && !reflect.DeepEqual(target.Interface(), key.Interface()) {
+		g.abort(c, invalid(map[string]string{jsonName(pk): "does not match path"}))
+		return
+	}
+	target.Set(key)
+
+	scope, ok := g.scope(c)
+	if !ok {
+		return
+	} else if errs, err := g.guardWrites(c, inst, nil, fieldNonZero(inst)); !g.abortGuardWrites(c, errs, err) {
+		return
+	} else if err := scope.stamp(inst); err != nil {
+		g.abort(c, err)
+		return
+	}
+	if g.Policy != nil && !g.Policy.CanCreate(c, inst) {
+		g.abortForbidden(c, false)
+		return
+	}
+
+	deleted, err := g.deletedAtField()
+	if err != nil {
+		g.abort(c, err)
+		return
+	}
+	upsert, err := g.upsertClause(scope, deleted)
+	if err != nil {
+		g.abort(c, err)
+		return
+	}
+
+	status := http.StatusCreated
+	byKey := clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Value: id}
+	err = g.db(c).Transaction(func(tx *gorm.DB) error {
+		// a record created since Fetch is replaced when it is in the scope, the upsert guards against later changes
+		existing := g.new()
+		if err := tx.Unscoped().Where(byKey).Take(existing).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			// created below
+		} else if err != nil {
+			return err
+		} else if softDeleted(deleted, existing) {
+			return &Error{Status: http.StatusConflict, Message: "record is deleted, restore it first"}
+		} else {
+			var count int64
+			if err := scope.where(tx.Model(g.new())).Where(byKey).Count(&count).Error; err != nil {
+				return err
+			} else if count == 0 {
+				return &Error{Status: http.StatusConflict, Message: "record already exists"}
+			}
+			status = http.StatusOK
+		}
+
+		if err := g.BeforeCreate.run(c, tx, inst); err != nil {
+			return err
+		}
+		if result := tx.Clauses(upsert).Create(inst); result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return &Error{Status: http.StatusConflict, Message: "record already exists"}
+		}
+		return g.AfterCreate.run(c, tx, inst)
+	})
+	if err != nil {
+		g.abort(c, err)
+		return
+	}
+
+	c.Status(status)
+	c.Set(g.Param, inst)
+}
+
+// Builds the ON CONFLICT clause replacing the writable columns of a record with the same primary key, guarded by the
+// scope so records of other scopes and soft deleted records are left untouched.
+func (g *Generator) upsertClause(scope scopeValues, deleted *schema.Field) (clause.OnConflict, error) {
+	s, err := g.schema()
+	if err != nil {
+		return clause.OnConflict{}, err
+	}
+
+	upsert := clause.OnConflict{}
+	var columns []string
+	for _, field := range s.Fields {
+		if field.PrimaryKey {
+			upsert.Columns = append(upsert.Columns, clause.Column{Name: field.DBName})
+		} else if _, scoped := scope[field]; field.DBName != "" && !scoped && (writable(field) || field.AutoUpdateTime > 0) {
+			columns = append(columns, field.DBName)
+		}
+	}
+	if len(columns) == 0 {
+		upsert.DoNothing = true
+		return upsert, nil
+	}
+	upsert.DoUpdates = clause.AssignmentColumns(columns)
+
+	for field, value := range scope {
+		upsert.Where.Exprs = append(upsert.Where.Exprs, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value})
+	}
+	if deleted != nil {
+		upsert.Where.Exprs = append(upsert.Where.Exprs, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: deleted.DBName}, Value: nil})
+	}
+	return upsert, nil
+}
@@ -0,0 +1,198 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+)
+
+// FieldError details a failed validation of a field
+type FieldError struct {
+	Tag     string `json:"tag"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"` // translated to the locale of the request
+}
+
+// Translations of validation messages, English unless a locale is registered with RegisterTranslation
+var translations = ut.New(en.New())
+
+// Validates the models of the handlers with their binding tags like gin does. It is private so that creating generators
+// leaves gin's binding.Validator alone, and names fields by their json names so messages name the keys they're
+// reported by.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "-" {
+			return name
+		}
+		return ""
+	})
+	trans, _ := translations.GetTranslator("en")
+	en_translations.RegisterDefaultTranslations(v, trans)
+	return v
+}
+
+// RegisterValidation adds a custom validation tag to the validator of the handlers, which is separate from gin's
+// binding.Validator.
+func RegisterValidation(tag string, fn validator.Func) error {
+	return validate.RegisterValidation(tag, fn)
+}
+
+// RegisterTranslation adds a locale that validation messages are translated to when a request accepts it, i.e.
+// RegisterTranslation(fr.New(), fr_translations.RegisterDefaultTranslations).
+func RegisterTranslation(locale locales.Translator, register func(v *validator.Validate, trans ut.Translator) error) error {
+	if err := translations.AddTranslator(locale, true); err != nil {
+		return err
+	}
+	trans, _ := translations.GetTranslator(locale.Locale())
+	return register(validate, trans)
+}
+
+// Decodes a JSON body like gin's JSON binding, without validating it
+func bindJSON(c *gin.Context, obj interface{}) error {
+	if c.Request == nil || c.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+	decoder := json.NewDecoder(c.Request.Body)
+	if binding.EnableDecoderUseNumber {
+		decoder.UseNumber()
+	}
+	if binding.EnableDecoderDisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder.Decode(obj)
+}
+
+// Validates a struct, or the struct a pointer refers to, like gin's default validator. Other values are valid.
+func validateStruct(obj interface{}) error {
+	value := reflect.ValueOf(obj)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	} else if value.CanAddr() {
+		return validate.Struct(value.Addr().Interface())
+	}
+	return validate.Struct(value.Interface())
+}
+
+// Finds the translator of the most preferred locale of the Accept-Language header, English by default
+func translator(c *gin.Context) ut.Translator {
+	type language struct {
+		locale string
+		q      float64
+	}
+	var accepted []language
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		params := strings.Split(part, ";")
+		lang := language{strings.ReplaceAll(strings.TrimSpace(params[0]), "-", "_"), 1}
+		for _, param := range params[1:] {
+			if value := strings.TrimSpace(param); strings.HasPrefix(value, "q=") {
+				lang.q, _ = strconv.ParseFloat(value[2:], 64)
+			}
+		}
+		if lang.locale != "" && lang.q > 0 {
+			accepted = append(accepted, lang)
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool { return accepted[i].q > accepted[j].q })
+
+	var locales []string
+	for _, lang := range accepted {
+		locales = append(locales, lang.locale)
+		if i := strings.Index(lang.locale, "_"); i > 0 {
+			locales = append(locales, lang.locale[:i]) // fr_CA falls back to fr
+		}
+	}
+	trans, _ := translations.FindTranslator(locales...)
+	return trans
+}
+
+// Converts binding and validation errors into field errors keyed by json path, i.e. animals[2].name. Failed validations
+// are detailed with their tag, param and a message in the locale of the request.
+func (g *Generator) validationError(c *gin.Context, err error) *Error {
+	if ve, ok := err.(validator.ValidationErrors); ok {
+		trans := translator(c)
+		e := invalid(make(map[string]string, len(ve)))
+		e.Fields = make(map[string]FieldError, len(ve))
+		for _, fieldErr := range ve {
+			path := jsonPath(g.model, fieldErr.StructNamespace())
+			e.Errors[path] = fieldErr.Tag()
+			e.Fields[path] = FieldError{fieldErr.Tag(), fieldErr.Param(), fieldErr.Translate(trans)}
+		}
+		return e
+	} else if te, ok := err.(*json.UnmarshalTypeError); ok {
+		return invalid(map[string]string{te.Field: "invalid " + te.Type.String() + " type"})
+	}
+	return invalid(map[string]string{"error": err.Error()})
+}
+
+// Converts the struct namespace of a validation error, i.e. Owner.Animals[2].Name, into the json path of the field.
+// Embedded structs are flattened like encoding/json does.
+func jsonPath(model reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")[1:] // without the model name
+	var path string
+	t := model
+	for _, segment := range segments {
+		name, indexes := segment, ""
+		if i := strings.Index(segment, "["); i >= 0 {
+			name, indexes = segment[:i], segment[i:]
+		}
+
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		jsonName := name
+		if t != nil && t.Kind() == reflect.Struct {
+			if field, ok := t.FieldByName(name); ok {
+				tag := strings.Split(field.Tag.Get("json"), ",")[0]
+				if field.Anonymous && tag == "" && indexes == "" {
+					t = field.Type
+					continue
+				} else if tag != "" && tag != "-" {
+					jsonName = tag
+				}
+				t = field.Type
+			} else {
+				t = nil
+			}
+		} else {
+			t = nil
+		}
+
+		if path != "" {
+			path += "."
+		}
+		path += jsonName + indexes
+		for i := strings.Count(indexes, "["); i > 0 && t != nil; i-- {
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				t = t.Elem()
+			default:
+				t = nil
+			}
+		}
+	}
+	return path
+}
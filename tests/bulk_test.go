@@ -0,0 +1,162 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+)
+
+// Registers the animal routes with the bulk handlers
+func bulkRouter(bestEffort bool) (*generator.Generator, *gin.Engine) {
+	g := generator.New(animalGenerator.DB, Animal{}, "animal")
+	g.Bulk = &generator.Bulk{BatchSize: 2, BestEffort: bestEffort}
+	router := gin.New()
+	g.Handlers(nil, nil).Register(router, "/animals")
+	return g, router
+}
+
+func TestBulkCreate(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	// nothing is saved when an item is invalid
+	g, router := bulkRouter(false)
+	resp := serve(router, "POST", "/animals/bulk", `[{"id": 90, "name": "Rex"}, {"id": "x", "name": "Max"}]`)
+	body, _ := io.ReadAll(resp.Body)
+	var count int64
+	if g.DB.Model(&Animal{}).Where("id = ?", 90).Count(&count); resp.Code != http.StatusBadRequest || count != 0 || string(body) != `{"message":"validation errors","errors":{"[1].id":"invalid uint type"}}` {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	resp = serve(router, "POST", "/animals/bulk", `[{"id": 90, "name": "Rex"}, {"id": 91, "name": "Max"}, {"id": 92, "name": "Fido"}]`)
+	body, _ = io.ReadAll(resp.Body)
+	animals := []Animal{}
+	if err := json.Unmarshal(body, &animals); resp.Code != http.StatusCreated || err != nil || len(animals) != 3 || animals[2].Name != "Fido" {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+}
+
+func TestBulkCreateBestEffort(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g, router := bulkRouter(true)
+
+	// the batch with the duplicate key is retried item by item
+	resp := serve(router, "POST", "/animals/bulk", `[{"id": "x"}, {"id": 90, "name": "Rex"}, {"id": 1, "name": "Max"}, {"id": 91, "name": "Fido"}]`)
+	body, _ := io.ReadAll(resp.Body)
+	result := struct {
+		Data   []*Animal         `json:"data"`
+		Errors map[string]string `json:"errors"`
+	}{}
+	if err := json.Unmarshal(body, &result); resp.Code != http.StatusMultiStatus || err != nil || len(result.Data) != 4 {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+	if result.Data[0] != nil || result.Data[1] == nil || result.Data[2] != nil || result.Data[3] == nil {
+		t.Errorf("incorrect data: %s", string(body))
+		return
+	} else if _, exists := result.Errors["[0].id"]; !exists {
+		t.Errorf("missing validation error: %s", string(body))
+		return
+	} else if result.Errors["[2].id"] != "already exists" {
+		t.Errorf("missing save error: %s", string(body))
+		return
+	}
+
+	var count int64
+	if g.DB.Model(&Animal{}).Where("id IN ?", []uint{90, 91}).Count(&count); count != 2 {
+		t.Errorf("saved %d animals", count)
+	}
+}
+
+func TestBulkPatch(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g, router := bulkRouter(false)
+
+	resp := serve(router, "PATCH", "/animals/bulk", `[{"id": 1, "name": "Rex"}, {"id": 2, "age": "old"}, {"id": 999}, {"name": "Max"}]`)
+	body, _ := io.ReadAll(resp.Body)
+	expected := `{"message":"validation errors","errors":{"[1].age":"invalid int type","[2]":"not found","[3].id":"required"}}`
+	animal := Animal{}
+	if g.DB.Take(&animal, 1); resp.Code != http.StatusBadRequest || string(body) != expected || animal.Name != "Alfred" {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	resp = serve(router, "PATCH", "/animals/bulk", `[{"id": 1, "name": "Rex"}, {"id": 2, "age": 0}]`)
+	body, _ = io.ReadAll(resp.Body)
+	animals := []Animal{}
+	if err := json.Unmarshal(body, &animals); resp.Code != http.StatusOK || err != nil || len(animals) != 2 {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+	bella := Animal{}
+	g.DB.Take(&bella, 2)
+	if g.DB.Take(&animal, 1); animal.Name != "Rex" || animal.Age != 2 || bella.Age != 0 || bella.Name != "Bella" {
+		t.Errorf("incorrect animals: %+v, %+v", animal, bella)
+	}
+}
+
+func TestBulkDelete(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g, router := bulkRouter(false)
+
+	resp := serve(router, "DELETE", "/animals?ids=1,x,2,2", "")
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusBadRequest || string(body) != `{"message":"validation errors","errors":{"[1]":"invalid uint type","[3]":"duplicate"}}` {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	var count int64
+	if resp := serve(router, "DELETE", "/animals?ids=1,2", ""); resp.Code != http.StatusNoContent {
+		t.Errorf("failed call with %d code", resp.Code)
+		return
+	} else if g.DB.Model(&Animal{}).Where("id IN ?", []uint{1, 2}).Count(&count); count != 0 {
+		t.Errorf("%d animals left", count)
+		return
+	}
+
+	// bulk routes are only registered when enabled
+	router = gin.New()
+	animalGenerator.Handlers(nil, nil).Register(router, "/animals")
+	if resp := serve(router, "DELETE", "/animals?ids=3", ""); resp.Code != http.StatusNotFound {
+		t.Errorf("bulk route registered with %d code", resp.Code)
+	}
+}
+
+func TestBulkPreconditions(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	g, router := bulkRouter(false)
+	if resp := serve(router, "DELETE", "/animals?ids=1,2", "", "If-Match", `"1"`); resp.Code != http.StatusPreconditionFailed {
+		t.Errorf("failed conditional call with %d code", resp.Code)
+		return
+	}
+
+	g.RequirePreconditions = true
+	for _, resp := range []*httptest.ResponseRecorder{
+		serve(router, "PATCH", "/animals/bulk", `[{"id": 1, "name": "Rex"}]`),
+		serve(router, "DELETE", "/animals?ids=1,2", ""),
+	} {
+		if resp.Code != http.StatusPreconditionRequired {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+			return
+		}
+	}
+
+	var count int64
+	if g.DB.Model(&Animal{}).Where("name IN ?", []string{"Alfred", "Bella"}).Count(&count); count != 2 {
+		t.Errorf("records changed without preconditions: %d", count)
+	}
+}
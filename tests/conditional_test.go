@@ -0,0 +1,110 @@
+package generator_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kennethklee/gin-gorm-rest/generator"
+	"gorm.io/gorm"
+)
+
+func TestRenderIfNoneMatch(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	animal := Animal{}
+	animalGenerator.DB.Take(&animal, 1)
+
+	req, _ := http.NewRequest("GET", "/animals/1", nil)
+	context, resp := mockContext(req)
+	context.Set("animal", &animal)
+	animalGenerator.Render()(context)
+	etag := resp.Header().Get("ETag")
+	if resp.Code != http.StatusOK || etag == "" {
+		t.Errorf("failed call with %d code and etag %s", resp.Code, etag)
+		return
+	}
+
+	for ifNoneMatch, expected := range map[string]int{`"stale"`: http.StatusOK, etag: http.StatusNotModified, `"stale", W/` + etag: http.StatusNotModified} {
+		req, _ = http.NewRequest("GET", "/animals/1", nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		context, resp = mockContext(req)
+		context.Set("animal", &animal)
+
+		animalGenerator.Render()(context)
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.Code != expected {
+			t.Errorf("failed call for %s with %d code: %s", ifNoneMatch, resp.Code, string(body))
+			return
+		}
+		if expected == http.StatusNotModified && (len(body) > 0 || resp.Header().Get("ETag") != etag) {
+			t.Errorf("incorrect not modified response for %s: %s", ifNoneMatch, string(body))
+			return
+		}
+	}
+}
+
+func TestRenderIfModifiedSince(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	updatedAt := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	vet := Vet{Model: gorm.Model{ID: 1, UpdatedAt: updatedAt}, Name: "Bob"}
+	vetGenerator := generator.New(animalGenerator.DB, Vet{}, "vet")
+
+	for since, expected := range map[time.Time]int{updatedAt.Add(-time.Hour): http.StatusOK, updatedAt: http.StatusNotModified, updatedAt.Add(time.Hour): http.StatusNotModified} {
+		req, _ := http.NewRequest("GET", "/vets/1", nil)
+		req.Header.Set("If-Modified-Since", since.Format(http.TimeFormat))
+		context, resp := mockContext(req)
+		context.Set("vet", &vet)
+
+		vetGenerator.Render()(context)
+
+		if resp.Code != expected {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed call for %s with %d code: %s", since, resp.Code, string(body))
+			return
+		}
+		if resp.Header().Get("Last-Modified") != updatedAt.Format(http.TimeFormat) {
+			t.Errorf("incorrect last modified: %s", resp.Header().Get("Last-Modified"))
+			return
+		}
+	}
+}
+
+func TestListIfNoneMatch(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	req, _ := http.NewRequest("GET", "/animals", nil)
+	context, resp := mockContext(req)
+	animalGenerator.List(nil)(context)
+	etag := resp.Header().Get("ETag")
+	if resp.Code != http.StatusOK || len(etag) < 2 || etag[:2] != "W/" {
+		t.Errorf("failed call with %d code and etag %s", resp.Code, etag)
+		return
+	}
+
+	req, _ = http.NewRequest("GET", "/animals", nil)
+	req.Header.Set("If-None-Match", etag)
+	context, resp = mockContext(req)
+	animalGenerator.List(nil)(context)
+	if resp.Code != http.StatusNotModified {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	// a change to the listing changes its etag
+	animalGenerator.DB.Model(&Animal{ID: 1}).Update("name", "renamed")
+	req, _ = http.NewRequest("GET", "/animals", nil)
+	req.Header.Set("If-None-Match", etag)
+	context, resp = mockContext(req)
+	animalGenerator.List(nil)(context)
+	if resp.Code != http.StatusOK || resp.Header().Get("ETag") == etag {
+		t.Errorf("failed call with %d code and etag %s", resp.Code, resp.Header().Get("ETag"))
+		return
+	}
+}
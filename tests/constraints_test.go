@@ -0,0 +1,48 @@
+package generator_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kennethklee/gin-gorm-rest/generator"
+)
+
+type Badge struct {
+	ID     uint    `json:"id" gorm:"primary_key"`
+	Code   string  `json:"code" gorm:"uniqueIndex"`
+	Holder *string `json:"holder" gorm:"not null"`
+	Level  int     `json:"level" gorm:"check:level >= 0"`
+}
+
+func TestConstraintErrors(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.DB.AutoMigrate(&Badge{})
+	holder := "Ann"
+	animalGenerator.DB.Create(&Badge{ID: 1, Code: "gold", Holder: &holder})
+	g := generator.New(animalGenerator.DB, Badge{}, "badge")
+
+	for _, test := range []struct {
+		body     string
+		status   int
+		expected string
+	}{
+		{`{"id": 1, "code": "silver", "holder": "Ben"}`, http.StatusConflict, `{"message":"already exists","errors":{"id":"already exists"}}`},
+		{`{"code": "gold", "holder": "Ben"}`, http.StatusConflict, `{"message":"already exists","errors":{"code":"already exists"}}`},
+		{`{"code": "silver"}`, http.StatusBadRequest, `{"message":"validation errors","errors":{"holder":"required"}}`},
+		{`{"code": "silver", "holder": "Ben", "level": -1}`, http.StatusUnprocessableEntity, `{"message":"constraint violated"}`},
+	} {
+		req, _ := http.NewRequest("POST", "/badges", strings.NewReader(test.body))
+		context, resp := mockContext(req)
+
+		g.Create()(context)
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.Code != test.status || string(body) != test.expected {
+			t.Errorf("failed call for %s with %d code: %s", test.body, resp.Code, string(body))
+			return
+		}
+	}
+}
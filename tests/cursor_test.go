@@ -0,0 +1,126 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/kennethklee/gin-gorm-rest/generator"
+)
+
+type cursorPage struct {
+	Data       []Animal `json:"data"`
+	NextCursor string   `json:"next_cursor"`
+	PrevCursor string   `json:"prev_cursor"`
+}
+
+func listCursorPage(t *testing.T, target string, parent *Owner) (cursorPage, bool) {
+	req, _ := http.NewRequest("GET", target, nil)
+	context, resp := mockContext(req)
+	if parent != nil {
+		context.Set("owner", *parent)
+		animalGenerator.ListAssociated(ownerAnimalAssoc, nil)(context)
+	} else {
+		animalGenerator.List(nil)(context)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	results := cursorPage{}
+	if resp.Code != http.StatusOK {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return results, false
+	}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return results, false
+	}
+	return results, true
+}
+
+func TestListCursorPaginatedModels(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Pagination = &generator.Pagination{Cursor: true, Envelope: true}
+	defer func() { animalGenerator.Pagination = nil }()
+
+	first, ok := listCursorPage(t, "/animals?limit=2", nil)
+	if !ok {
+		return
+	}
+	if len(first.Data) != 2 || first.Data[0].Name != "Alfred" || first.Data[1].Name != "Bella" || first.NextCursor == "" || first.PrevCursor != "" {
+		t.Errorf("failed first page: %+v", first)
+		return
+	}
+
+	second, ok := listCursorPage(t, "/animals?limit=2&cursor="+url.QueryEscape(first.NextCursor), nil)
+	if !ok {
+		return
+	}
+	if len(second.Data) != 2 || second.Data[0].Name != "Charlie" || second.Data[1].Name != "Daisy" || second.NextCursor == "" || second.PrevCursor == "" {
+		t.Errorf("failed second page: %+v", second)
+		return
+	}
+
+	previous, ok := listCursorPage(t, "/animals?limit=2&cursor="+url.QueryEscape(second.PrevCursor), nil)
+	if !ok {
+		return
+	}
+	if len(previous.Data) != 2 || previous.Data[0].Name != "Alfred" || previous.Data[1].Name != "Bella" || previous.PrevCursor != "" {
+		t.Errorf("failed previous page: %+v", previous)
+		return
+	}
+
+	last, ok := listCursorPage(t, "/animals?limit=4&cursor="+url.QueryEscape(first.NextCursor), nil)
+	if !ok {
+		return
+	}
+	if len(last.Data) != 4 || last.Data[3].Name != "Fred" || last.NextCursor != "" {
+		t.Errorf("failed last page: %+v", last)
+		return
+	}
+}
+
+func TestListAssociatedCursorPaginatedModels(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Pagination = &generator.Pagination{Cursor: true, CursorKeys: []string{"species", "id"}, Envelope: true}
+	defer func() { animalGenerator.Pagination = nil }()
+
+	first, ok := listCursorPage(t, "/owners/1/animals?limit=2", &Owner{ID: 1})
+	if !ok {
+		return
+	}
+	if len(first.Data) != 2 || first.Data[0].Name != "Alfred" || first.Data[1].Name != "Charlie" {
+		t.Errorf("failed first page: %+v", first)
+		return
+	}
+
+	second, ok := listCursorPage(t, "/owners/1/animals?limit=2&cursor="+url.QueryEscape(first.NextCursor), &Owner{ID: 1})
+	if !ok {
+		return
+	}
+	if len(second.Data) != 1 || second.Data[0].Name != "Bella" || second.NextCursor != "" {
+		t.Errorf("failed second page: %+v", second)
+		return
+	}
+}
+
+func TestListTamperedCursor(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Pagination = &generator.Pagination{Cursor: true}
+	defer func() { animalGenerator.Pagination = nil }()
+
+	req, _ := http.NewRequest("GET", "/animals?cursor=eyJ2IjpbIjMiXX0.c2lnbmF0dXJl", nil)
+	context, resp := mockContext(req)
+
+	animalGenerator.List(nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+}
@@ -0,0 +1,70 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+	"gorm.io/gorm"
+)
+
+func TestErrorNotFound(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/animals/999", nil)
+	context, resp := mockContext(req)
+	context.Params = gin.Params{gin.Param{Key: "animal", Value: "999"}}
+
+	animalGenerator.Fetch()(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusNotFound || string(body) != `{"message":"not found"}` {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+	}
+}
+
+func TestProblemErrors(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	g := generator.New(animalGenerator.DB, Animal{}, "animal")
+	g.ErrorRenderer = generator.ProblemErrorRenderer
+	g.BeforeCreate = func(c *gin.Context, tx *gorm.DB, model interface{}) error {
+		if model.(*Animal).Species == "dragon" {
+			return &generator.Error{Status: http.StatusConflict, Code: "mythical", Message: "dragons are not animals"}
+		}
+		return nil
+	}
+
+	for _, test := range []struct {
+		body     string
+		expected generator.ProblemDetails
+	}{
+		{`{"name": 1}`, generator.ProblemDetails{Title: "Bad Request", Status: 400, Detail: "validation errors", Code: "validation_failed", Errors: map[string]string{"name": "invalid string type"}}},
+		{`{"name": "Puff", "species": "dragon"}`, generator.ProblemDetails{Title: "Conflict", Status: 409, Detail: "dragons are not animals", Code: "mythical"}},
+	} {
+		req, _ := http.NewRequest("POST", "/animals", strings.NewReader(test.body))
+		context, resp := mockContext(req)
+
+		g.Create()(context)
+
+		body, _ := io.ReadAll(resp.Body)
+		problem := generator.ProblemDetails{}
+		if err := json.Unmarshal(body, &problem); err != nil || resp.Code != test.expected.Status || resp.Header().Get("Content-Type") != generator.MIMEProblemJSON {
+			t.Errorf("failed call for %s with %d code: %s", test.body, resp.Code, string(body))
+			return
+		}
+		if problem.Title != test.expected.Title || problem.Detail != test.expected.Detail || problem.Code != test.expected.Code || len(problem.Errors) != len(test.expected.Errors) {
+			t.Errorf("incorrect problem for %s: %s", test.body, string(body))
+			return
+		}
+		for key, msg := range test.expected.Errors {
+			if problem.Errors[key] != msg {
+				t.Errorf("incorrect problem for %s: %s", test.body, string(body))
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package generator_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+	"gorm.io/gorm"
+)
+
+type Ticket struct {
+	ID      uint   `json:"id" gorm:"primary_key"`
+	Title   string `json:"title"`
+	Version int    `json:"version"`
+}
+
+func TestUpdateIfMatch(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	// fetch for the etag
+	req, _ := http.NewRequest("GET", "/animals/1", nil)
+	context, resp := mockContext(req)
+	context.Params = gin.Params{gin.Param{Key: "animal", Value: "1"}}
+	animalGenerator.Fetch()(context)
+	etag := resp.Header().Get("ETag")
+	if !strings.HasPrefix(etag, `"`) {
+		t.Errorf("missing strong etag: %s", etag)
+		return
+	}
+
+	for ifMatch, expected := range map[string]int{`"stale"`: http.StatusPreconditionFailed, `W/` + etag: http.StatusPreconditionFailed, etag: http.StatusOK} {
+		animal := Animal{}
+		animalGenerator.DB.Take(&animal, 1)
+
+		req, _ = http.NewRequest("PATCH", "/animals/1", strings.NewReader(`{"age": 9}`))
+		req.Header.Set("If-Match", ifMatch)
+		context, resp = mockContext(req)
+		context.Set("animal", &animal)
+
+		animalGenerator.Patch()(context)
+
+		if context.Writer.Status() != expected {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed call for %s with %d code: %s", ifMatch, context.Writer.Status(), string(body))
+			return
+		}
+	}
+}
+
+func TestDeletePreconditionRequired(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.RequirePreconditions = true
+	defer func() { animalGenerator.RequirePreconditions = false }()
+
+	req, _ := http.NewRequest("DELETE", "/animals/1", nil)
+	context, resp := mockContext(req)
+	context.Set("animal", &Animal{ID: 1})
+
+	animalGenerator.Delete()(context)
+
+	if resp.Code != http.StatusPreconditionRequired {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+}
+
+func TestUpdateVersionConflict(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.DB.AutoMigrate(&Ticket{})
+	animalGenerator.DB.Create(&Ticket{ID: 1, Title: "first", Version: 2})
+	ticketGenerator := generator.New(animalGenerator.DB, Ticket{}, "ticket")
+	ticketGenerator.VersionField = "version"
+
+	// stale copy fetched before another update
+	req, _ := http.NewRequest("PUT", "/tickets/1", strings.NewReader(`{"title": "stale"}`))
+	context, resp := mockContext(req)
+	context.Set("ticket", &Ticket{ID: 1, Title: "first", Version: 1})
+
+	ticketGenerator.Update(nil)(context)
+
+	if context.Writer.Status() != http.StatusPreconditionFailed {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s", context.Writer.Status(), string(body))
+		return
+	}
+
+	// current copy
+	req, _ = http.NewRequest("PUT", "/tickets/1", strings.NewReader(`{"title": "second"}`))
+	req.Header.Set("If-Match", `"2"`)
+	context, resp = mockContext(req)
+	context.Set("ticket", &Ticket{ID: 1, Title: "first", Version: 2})
+
+	ticketGenerator.Update(nil)(context)
+	ticketGenerator.Render()(context)
+
+	if context.Writer.Status() != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s", context.Writer.Status(), string(body))
+		return
+	}
+	if etag := resp.Header().Get("ETag"); etag != `"3"` {
+		t.Errorf("incorrect etag: %s", etag)
+		return
+	}
+
+	finalTicket := Ticket{}
+	animalGenerator.DB.Take(&finalTicket, 1)
+	if finalTicket.Title != "second" || finalTicket.Version != 3 {
+		t.Errorf("incorrect db record: %+v", finalTicket)
+		return
+	}
+}
+
+func TestFetchFieldsValidators(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.DB.AutoMigrate(&Ticket{}, &Vet{})
+	animalGenerator.DB.Create(&Ticket{ID: 1, Title: "first", Version: 3})
+	updatedAt := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	animalGenerator.DB.Create(&Vet{Model: gorm.Model{ID: 1, UpdatedAt: updatedAt}, Name: "Bob"})
+	ticketGenerator := generator.New(animalGenerator.DB, Ticket{}, "ticket")
+	ticketGenerator.VersionField = "version"
+	vetGenerator := generator.New(animalGenerator.DB, Vet{}, "vet")
+
+	// sparse fieldsets still load the columns of the ETag and Last-Modified headers
+	for _, test := range []struct {
+		g      *generator.Generator
+		path   string
+		header string
+		value  string
+	}{
+		{ticketGenerator, "/tickets/1?fields=title", "ETag", `"3"`},
+		{vetGenerator, "/vets/1?fields=name", "Last-Modified", updatedAt.Format(http.TimeFormat)},
+	} {
+		req, _ := http.NewRequest("GET", test.path, nil)
+		context, resp := mockContext(req)
+		context.Params = gin.Params{gin.Param{Key: test.g.Param, Value: "1"}}
+
+		test.g.Fetch()(context)
+		test.g.Render()(context)
+
+		if resp.Code != http.StatusOK || resp.Header().Get(test.header) != test.value {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed call for %s with %d code and %s %q: %s", test.path, resp.Code, test.header, resp.Header().Get(test.header), string(body))
+			return
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/vets?fields=name", nil)
+	context, resp := mockContext(req)
+	vetGenerator.List(nil)(context)
+	if resp.Code != http.StatusOK || resp.Header().Get("Last-Modified") != updatedAt.Format(http.TimeFormat) {
+		t.Errorf("failed list with %d code and Last-Modified %q", resp.Code, resp.Header().Get("Last-Modified"))
+	}
+}
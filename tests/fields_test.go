@@ -0,0 +1,94 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+)
+
+func TestListSparseFields(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	req, _ := http.NewRequest("GET", "/animals?fields=name,age", nil)
+	context, resp := mockContext(req)
+
+	animalGenerator.List(nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusOK {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := []map[string]interface{}{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+
+	if len(results) != 6 || len(results[0]) != 2 || results[0]["name"] != "Alfred" || results[0]["age"] != 2.0 {
+		t.Errorf("failed response: %s", string(body))
+		return
+	}
+}
+
+func TestFetchAndRenderSparseFields(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	req, _ := http.NewRequest("GET", "/animals/1?fields=name", nil)
+	context, resp := mockContext(req)
+	context.Params = gin.Params{gin.Param{Key: "animal", Value: "1"}}
+
+	animalGenerator.Fetch()(context)
+	animal := context.MustGet("animal").(*Animal)
+	if animal.ID != 1 || animal.Name != "Alfred" || animal.Species != "" {
+		t.Errorf("incorrect selected columns: %+v", animal)
+		return
+	}
+
+	animalGenerator.Render()(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusOK {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+	if string(body) != `{"name":"Alfred"}` {
+		t.Errorf("failed response: %s", string(body))
+		return
+	}
+}
+
+func TestListHiddenField(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Fields = []string{"id", "name"}
+	defer func() { animalGenerator.Fields = nil }()
+
+	req, _ := http.NewRequest("GET", "/animals?fields=id,age", nil)
+	context, resp := mockContext(req)
+
+	animalGenerator.List(nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := generator.ValidationErrorResponse{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+	if results.Errors["fields"] != "unknown field age" {
+		t.Errorf("failed response: %s", string(body))
+		return
+	}
+}
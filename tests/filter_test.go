@@ -0,0 +1,98 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/kennethklee/gin-gorm-rest/generator"
+)
+
+func TestListFilteredModels(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Filters = []string{"species", "age"}
+	defer func() { animalGenerator.Filters = nil }()
+
+	req, _ := http.NewRequest("GET", "/animals?species=cat&age[gte]=3", nil)
+	context, resp := mockContext(req)
+
+	animalGenerator.List(nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusOK {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := []Animal{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+
+	if len(results) != 2 || results[0].Name != "Charlie" || results[1].Name != "Fred" {
+		t.Errorf("failed response: %s", string(body))
+		return
+	}
+}
+
+func TestListAssociatedFilteredModels(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Filters = []string{"*"}
+	defer func() { animalGenerator.Filters = nil }()
+
+	req, _ := http.NewRequest("GET", "/owners/1/animals?name[like]=%25a%25&species[in]=dog,bird", nil)
+	context, resp := mockContext(req)
+	context.Set("owner", Owner{ID: 1})
+
+	animalGenerator.ListAssociated(ownerAnimalAssoc, nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusOK {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := []Animal{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+
+	if len(results) != 1 || results[0].Name != "Bella" {
+		t.Errorf("failed response: %s", string(body))
+		return
+	}
+}
+
+func TestListFilterErrors(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Filters = []string{"species", "age"}
+	defer func() { animalGenerator.Filters = nil }()
+
+	req, _ := http.NewRequest("GET", "/animals?name=Alfred&age[between]=1&species[in]=cat", nil)
+	context, resp := mockContext(req)
+
+	animalGenerator.List(nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := generator.ValidationErrorResponse{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+
+	if results.Errors["name"] != "unknown field" || results.Errors["age[between]"] != "unknown operator" || len(results.Errors) != 2 {
+		t.Errorf("failed response: %s", string(body))
+		return
+	}
+}
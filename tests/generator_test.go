@@ -65,6 +65,17 @@ func mockContext(req *http.Request) (*gin.Context, *httptest.ResponseRecorder) {
 	return context, resp
 }
 
+// Serves a request through a router, with the headers given as name and value pairs
+func serve(router *gin.Engine, method string, path string, body string, headers ...string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
 func createFixture(file string, models interface{}) error {
 	// Open json file
 	f, err := os.Open(file)
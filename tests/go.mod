@@ -4,6 +4,8 @@ go 1.18
 
 require (
 	github.com/gin-gonic/gin v1.8.1
+	github.com/go-playground/locales v0.14.0
+	github.com/go-playground/validator/v10 v10.11.0
 	github.com/kennethklee/gin-gorm-rest/generator v0.0.0-unpublished
 	gorm.io/driver/sqlite v1.3.5
 	gorm.io/gorm v1.23.7
@@ -13,9 +15,7 @@ replace github.com/kennethklee/gin-gorm-rest/generator v0.0.0-unpublished => ../
 
 require (
 	github.com/gin-contrib/sse v0.1.0 // indirect
-	github.com/go-playground/locales v0.14.0 // indirect
 	github.com/go-playground/universal-translator v0.18.0 // indirect
-	github.com/go-playground/validator/v10 v10.11.0 // indirect
 	github.com/goccy/go-json v0.9.7 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
@@ -0,0 +1,196 @@
+package generator_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+	"gorm.io/gorm"
+)
+
+func TestCreateBeforeHookAbort(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.BeforeCreate = func(c *gin.Context, tx *gorm.DB, model interface{}) error {
+		if model.(*Animal).Species == "dragon" {
+			return &generator.Error{Status: http.StatusUnprocessableEntity, Message: "validation errors", Errors: map[string]string{"species": "extinct"}}
+		}
+		return nil
+	}
+	defer func() { animalGenerator.BeforeCreate = nil }()
+
+	req, _ := http.NewRequest("POST", "/animals", strings.NewReader(`{"id": 99, "name": "Puff", "species": "dragon", "age": 1000}`))
+	context, resp := mockContext(req)
+
+	animalGenerator.Create()(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusUnprocessableEntity || string(body) != `{"message":"validation errors","errors":{"species":"extinct"}}` {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+	var count int64
+	if animalGenerator.DB.Model(&Animal{}).Where("id = ?", 99).Count(&count); count != 0 {
+		t.Errorf("record was created")
+	}
+}
+
+func TestCreateAfterHookRollback(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.AfterCreate = func(c *gin.Context, tx *gorm.DB, model interface{}) error {
+		return errors.New("audit failed")
+	}
+	defer func() { animalGenerator.AfterCreate = nil }()
+
+	req, _ := http.NewRequest("POST", "/animals", strings.NewReader(`{"id": 99, "name": "Puff", "species": "cat", "age": 1}`))
+	context, resp := mockContext(req)
+
+	animalGenerator.Create()(context)
+
+	if resp.Code != http.StatusInternalServerError {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+	var count int64
+	if animalGenerator.DB.Model(&Animal{}).Where("id = ?", 99).Count(&count); count != 0 {
+		t.Errorf("create was not rolled back")
+	}
+}
+
+func TestUpdateHooksSnapshot(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g := generator.NewOf[Animal](animalGenerator.DB, "animal")
+	var before, after string
+	g.OnBeforeUpdate(func(c *gin.Context, tx *gorm.DB, old *Animal, animal *Animal) error {
+		before = old.Name + ">" + animal.Name
+		animal.Age = 10 // hooks may change the model before it is saved
+		return nil
+	})
+	g.OnAfterUpdate(func(c *gin.Context, tx *gorm.DB, old *Animal, animal *Animal) error {
+		stored := Animal{}
+		tx.Take(&stored, animal.ID)
+		after = old.Name + ">" + stored.Name
+		return nil
+	})
+
+	for method, handler := range map[string]gin.HandlerFunc{"PUT": g.Update(nil), "PATCH": g.Patch()} {
+		before, after = "", ""
+		animal := Animal{}
+		animalGenerator.DB.Take(&animal, 1)
+		expected := animal.Name + ">" + method
+
+		req, _ := http.NewRequest(method, "/animals/1", strings.NewReader(`{"name": "`+method+`"}`))
+		context, resp := mockContext(req)
+		context.Set("animal", &animal)
+
+		handler(context)
+
+		if resp.Code != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed %s call with %d code: %s", method, resp.Code, string(body))
+			return
+		}
+		if before != expected || after != expected {
+			t.Errorf("incorrect %s snapshots: %s, %s", method, before, after)
+			return
+		}
+		final := Animal{}
+		if animalGenerator.DB.Take(&final, 1); final.Name != method || final.Age != 10 {
+			t.Errorf("incorrect %s db record: %+v", method, final)
+			return
+		}
+	}
+}
+
+func TestDeleteBeforeHookAbort(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.BeforeDelete = func(c *gin.Context, tx *gorm.DB, model interface{}) error {
+		return &generator.Error{Status: http.StatusConflict, Message: "animal has appointments"}
+	}
+	defer func() { animalGenerator.BeforeDelete = nil }()
+
+	req, _ := http.NewRequest("DELETE", "/animals/1", nil)
+	context, resp := mockContext(req)
+	context.Set("animal", &Animal{ID: 1})
+
+	animalGenerator.Delete()(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusConflict || string(body) != `{"message":"animal has appointments"}` {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+	var count int64
+	if animalGenerator.DB.Model(&Animal{}).Where("id = ?", 1).Count(&count); count != 1 {
+		t.Errorf("record was deleted")
+	}
+}
+
+func TestFetchAfterHook(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.AfterFetch = func(c *gin.Context, tx *gorm.DB, model interface{}) error {
+		if c.GetHeader("X-Role") != "vet" {
+			return &generator.Error{Status: http.StatusForbidden, Message: "forbidden"}
+		}
+		model.(*Animal).Name = strings.ToUpper(model.(*Animal).Name)
+		return nil
+	}
+	defer func() { animalGenerator.AfterFetch = nil }()
+
+	for role, expected := range map[string]int{"": http.StatusForbidden, "vet": http.StatusOK} {
+		req, _ := http.NewRequest("GET", "/animals/1", nil)
+		req.Header.Set("X-Role", role)
+		context, resp := mockContext(req)
+		context.Params = gin.Params{gin.Param{Key: "animal", Value: "1"}}
+
+		animalGenerator.Fetch()(context)
+
+		if resp.Code != expected || context.IsAborted() != (expected != http.StatusOK) {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed call for %s with %d code: %s", role, resp.Code, string(body))
+			return
+		}
+		if expected == http.StatusOK && context.MustGet("animal").(*Animal).Name != "ALFRED" {
+			t.Errorf("incorrect model: %+v", context.MustGet("animal"))
+			return
+		}
+	}
+}
+
+func TestTypedHooks(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g := generator.NewOf[Animal](animalGenerator.DB, "animal")
+	var created, fetched string
+	g.OnBeforeCreate(func(c *gin.Context, tx *gorm.DB, animal *Animal) error {
+		created = animal.Name
+		return nil
+	})
+	g.OnAfterFetch(func(c *gin.Context, tx *gorm.DB, animal *Animal) error {
+		fetched = animal.Name
+		return nil
+	})
+
+	req, _ := http.NewRequest("POST", "/animals", strings.NewReader(`{"id": 99, "name": "Puff", "species": "cat", "age": 1}`))
+	context, _ := mockContext(req)
+	g.Create()(context)
+	status := context.Writer.Status()
+
+	req, _ = http.NewRequest("GET", "/animals/99", nil)
+	context, _ = mockContext(req)
+	context.Params = gin.Params{gin.Param{Key: "animal", Value: "99"}}
+	g.Fetch()(context)
+
+	if status != http.StatusCreated || created != "Puff" || fetched != "Puff" {
+		t.Errorf("failed call with %d code: %q, %q", status, created, fetched)
+	}
+}
@@ -0,0 +1,145 @@
+package generator_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+	"gorm.io/gorm"
+)
+
+// Registers the animals of an owner with an idempotency store
+func idempotentRouter(store generator.IdempotencyStore) *gin.Engine {
+	owners := generator.New(animalGenerator.DB, Owner{}, "owner")
+	animals := generator.New(animalGenerator.DB, Animal{}, "animal")
+	animals.Idempotency = store
+	router := gin.New()
+	animals.AssociatedHandlers(ownerAnimalAssoc, nil, nil).Register(router, "/owners/:owner/animals", owners.Fetch())
+	return router
+}
+
+func TestIdempotentCreate(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	store, err := generator.NewGormIdempotencyStore(animalGenerator.DB, time.Hour)
+	if err != nil {
+		t.Errorf("failed to create store: %s", err)
+		return
+	}
+	for name, store := range map[string]generator.IdempotencyStore{"memory": generator.NewMemoryIdempotencyStore(time.Hour), "gorm": store} {
+		router := idempotentRouter(store)
+		key := "create-" + name
+
+		first := serve(router, "POST", "/owners/1/animals", `{"name": "Puff", "species": "`+name+`"}`, generator.IdempotencyKeyHeader, key)
+		firstBody, _ := io.ReadAll(first.Body)
+		retry := serve(router, "POST", "/owners/1/animals", `{"name": "Puff", "species": "`+name+`"}`, generator.IdempotencyKeyHeader, key)
+		retryBody, _ := io.ReadAll(retry.Body)
+		if first.Code != http.StatusCreated || retry.Code != http.StatusCreated || string(firstBody) != string(retryBody) {
+			t.Errorf("failed %s calls with %d and %d codes: %s, %s", name, first.Code, retry.Code, string(firstBody), string(retryBody))
+			return
+		} else if retry.Header().Get(generator.IdempotencyReplayedHeader) != "true" {
+			t.Errorf("%s response was not replayed", name)
+			return
+		}
+
+		var count int64
+		if animalGenerator.DB.Model(&Animal{}).Where("species = ?", name).Count(&count); count != 1 {
+			t.Errorf("created %d animals with the %s store", count, name)
+			return
+		}
+
+		if resp := serve(router, "POST", "/owners/1/animals", `{"name": "Fluff"}`, generator.IdempotencyKeyHeader, key); resp.Code != http.StatusUnprocessableEntity {
+			t.Errorf("failed %s call with another body with %d code", name, resp.Code)
+			return
+		}
+
+		// a concurrent duplicate
+		store.Reserve(context.Background(), "pending-"+name, "")
+		if resp := serve(router, "POST", "/owners/1/animals", `{"name": "Puff"}`, generator.IdempotencyKeyHeader, "pending-"+name); resp.Code != http.StatusConflict {
+			t.Errorf("failed %s call in progress with %d code", name, resp.Code)
+			return
+		}
+	}
+}
+
+func TestIdempotencyKeysPerTenant(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	tenant := func(c *gin.Context) string { return c.GetHeader("X-Owner") }
+	for name, scoped := range map[string]func(g *generator.Generator){
+		"scope": func(g *generator.Generator) {
+			g.Scope = func(c *gin.Context) (map[string]interface{}, error) {
+				return map[string]interface{}{"owner_id": tenant(c)}, nil
+			}
+		},
+		"principal": func(g *generator.Generator) { g.IdempotencyScope = tenant },
+	} {
+		g := generator.New(animalGenerator.DB, Animal{}, "animal")
+		g.Idempotency = generator.NewMemoryIdempotencyStore(time.Hour)
+		scoped(g)
+		router := gin.New()
+		g.Handlers(nil, nil).Register(router, "/animals")
+
+		key := "invoice-" + name
+		first := serve(router, "POST", "/animals", `{"name": "Puff", "owner_id": 2}`, generator.IdempotencyKeyHeader, key, "X-Owner", "2")
+		firstBody, _ := io.ReadAll(first.Body)
+		other := serve(router, "POST", "/animals", `{"name": "Puff", "owner_id": 2}`, generator.IdempotencyKeyHeader, key, "X-Owner", "3")
+		otherBody, _ := io.ReadAll(other.Body)
+
+		if first.Code != http.StatusCreated || other.Header().Get(generator.IdempotencyReplayedHeader) != "" || string(otherBody) == string(firstBody) {
+			t.Errorf("replayed %s response of another tenant: %s, %s", name, string(firstBody), string(otherBody))
+			return
+		}
+	}
+}
+
+func TestIdempotentCreateTransaction(t *testing.T) {
+	store, err := generator.NewGormIdempotencyStore(origDB, time.Hour)
+	if err != nil {
+		t.Errorf("failed to create store: %s", err)
+		return
+	}
+	defer origDB.Where("species LIKE ?", "tx-%").Delete(&Animal{})
+	defer origDB.Where(&generator.IdempotencyKey{Key: "tx-gorm"}).Delete(&generator.IdempotencyKey{})
+
+	for name, store := range map[string]generator.IdempotencyStore{"memory": generator.NewMemoryIdempotencyStore(time.Hour), "gorm": store} {
+		animals := generator.New(origDB, Animal{}, "animal")
+		animals.Idempotency = store
+		failCommit := true
+		router := gin.New()
+		// the test transaction would nest it in a savepoint, which has no commit of its own
+		router.POST("/animals", generator.Transaction(origDB), animals.Create(), animals.Render(), func(c *gin.Context) {
+			if failCommit {
+				c.MustGet(generator.TxKey).(*gorm.DB).Rollback()
+			}
+		})
+		body, key := `{"name": "Puff", "species": "tx-`+name+`"}`, "tx-"+name
+
+		// the key is only completed once committed
+		if resp := serve(router, "POST", "/animals", body, generator.IdempotencyKeyHeader, key); resp.Code != http.StatusInternalServerError {
+			t.Errorf("failed %s call with %d code", name, resp.Code)
+			return
+		}
+		failCommit = false
+		for i, expected := range []string{"", "true"} {
+			resp := serve(router, "POST", "/animals", body, generator.IdempotencyKeyHeader, key)
+			if resp.Code != http.StatusCreated || resp.Header().Get(generator.IdempotencyReplayedHeader) != expected {
+				respBody, _ := io.ReadAll(resp.Body)
+				t.Errorf("failed %s retry %d with %d code: %s", name, i, resp.Code, string(respBody))
+				return
+			}
+		}
+
+		var count int64
+		if origDB.Model(&Animal{}).Where("species = ?", "tx-"+name).Count(&count); count != 1 {
+			t.Errorf("created %d animals with the %s store", count, name)
+			return
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+	"gorm.io/gorm"
+)
+
+func TestListIncludedAssociations(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	ownerGenerator.Includes = map[string]generator.Include{"animals": {Filters: []string{"species"}, MaxLimit: 10}}
+	defer func() { ownerGenerator.Includes = nil }()
+
+	req, _ := http.NewRequest("GET", "/owners?include=animals&include[animals][species]=cat&include[animals][limit]=1", nil)
+	context, resp := mockContext(req)
+
+	ownerGenerator.List(func(ctx *gin.Context, qs *gorm.DB) bool {
+		qs.Order("id asc")
+		return true
+	})(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusOK {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := []Owner{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+
+	expected := []string{"Alfred", "Ella", "Fred"}
+	if len(results) != len(expected) {
+		t.Errorf("failed count: %s", string(body))
+		return
+	}
+	for i, name := range expected {
+		if len(results[i].Animals) != 1 || results[i].Animals[0].Name != name {
+			t.Errorf("failed response at %d: %s", i, string(body))
+			return
+		}
+	}
+}
+
+func TestFetchIncludedNestedAssociations(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Includes = map[string]generator.Include{"owner": {}, "owner.animals": {}}
+	defer func() { animalGenerator.Includes = nil }()
+
+	req, _ := http.NewRequest("GET", "/animals/5?include=owner.animals&fields=name", nil)
+	context, resp := mockContext(req)
+	context.Params = gin.Params{gin.Param{Key: "animal", Value: "5"}}
+
+	animalGenerator.Fetch()(context)
+	animalGenerator.Render()(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusOK {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+	if len(results) != 2 || string(results["name"]) != `"Ella"` {
+		t.Errorf("failed response: %s", string(body))
+		return
+	}
+
+	owner := Owner{}
+	if err := json.Unmarshal(results["owner"], &owner); err != nil {
+		t.Errorf("failed owner decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+	if owner.Name != "Yee" || len(owner.Animals) != 2 {
+		t.Errorf("failed included owner: %s", string(body))
+		return
+	}
+}
+
+func TestListIncludeErrors(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Includes = map[string]generator.Include{"*": {}}
+	defer func() { animalGenerator.Includes = nil }()
+
+	for query, expected := range map[string]string{
+		"include=owner.animals.owner":            "too deep owner.animals.owner",
+		"include=vet":                            "unknown association vet",
+		"include=owner&include[owner][name]=Zim": "unknown field",
+	} {
+		req, _ := http.NewRequest("GET", "/animals?"+query, nil)
+		context, resp := mockContext(req)
+
+		animalGenerator.List(nil)(context)
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.Code != http.StatusBadRequest {
+			t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+			return
+		}
+
+		results := generator.ValidationErrorResponse{}
+		if err := json.Unmarshal(body, &results); err != nil {
+			t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+			return
+		}
+		if results.Errors["include"] != expected && results.Errors["include[owner][name]"] != expected {
+			t.Errorf("failed response for %s: %s", query, string(body))
+			return
+		}
+	}
+}
+
+func TestIncludeMaxLimitByDefault(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	ownerGenerator.Includes = map[string]generator.Include{"animals": {MaxLimit: 2}}
+	defer func() { ownerGenerator.Includes = nil }()
+
+	for query, expected := range map[string][]string{
+		"include=animals": {"Alfred", "Bella"},
+		"include=animals&include[animals][limit]=1": {"Alfred"},
+		"include=animals&include[animals][limit]=9": {"Alfred", "Bella"},
+	} {
+		req, _ := http.NewRequest("GET", "/owners/1?"+query, nil)
+		context, resp := mockContext(req)
+		context.Params = gin.Params{gin.Param{Key: "owner", Value: "1"}}
+
+		ownerGenerator.Fetch()(context)
+		ownerGenerator.Render()(context)
+
+		body, _ := io.ReadAll(resp.Body)
+		owner := Owner{}
+		if err := json.Unmarshal(body, &owner); err != nil || resp.Code != http.StatusOK || len(owner.Animals) != len(expected) {
+			t.Errorf("failed call for %s with %d code: %s", query, resp.Code, string(body))
+			return
+		}
+		for i, name := range expected {
+			if owner.Animals[i].Name != name {
+				t.Errorf("failed response for %s: %s", query, string(body))
+				return
+			}
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/owners?include=animals", nil)
+	context, resp := mockContext(req)
+
+	ownerGenerator.List(nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	results := []Owner{}
+	if err := json.Unmarshal(body, &results); err != nil || resp.Code != http.StatusOK || len(results) == 0 {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+	for _, owner := range results {
+		if len(owner.Animals) > 2 {
+			t.Errorf("failed limit of owner %d: %s", owner.ID, string(body))
+			return
+		}
+	}
+}
+
+type Account struct {
+	ID       uint   `json:"id" gorm:"primary_key"`
+	Name     string `json:"name"`
+	Password string `json:"password" rest:"writeonly"`
+}
+type Post struct {
+	ID        uint     `json:"id" gorm:"primary_key"`
+	AccountID uint     `json:"account_id"`
+	Acct      *Account `json:"acct,omitempty" gorm:"foreignkey:AccountID"`
+}
+
+func TestIncludeWriteOnlyFields(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.DB.AutoMigrate(&Account{}, &Post{})
+	animalGenerator.DB.Create(&Account{ID: 1, Name: "ann", Password: "hunter2"})
+	animalGenerator.DB.Create(&Post{ID: 1, AccountID: 1})
+	g := generator.New(animalGenerator.DB, Post{}, "post")
+	g.Includes = map[string]generator.Include{"acct": {}}
+
+	for _, query := range []string{"", "&fields=id"} {
+		req, _ := http.NewRequest("GET", "/posts?include=acct"+query, nil)
+		context, resp := mockContext(req)
+		g.List(nil)(context)
+		list, _ := io.ReadAll(resp.Body)
+
+		req, _ = http.NewRequest("GET", "/posts/1?include=acct"+query, nil)
+		context, resp = mockContext(req)
+		context.Params = gin.Params{gin.Param{Key: "post", Value: "1"}}
+		g.Fetch()(context)
+		g.Render()(context)
+		fetched, _ := io.ReadAll(resp.Body)
+
+		for _, body := range []string{string(list), string(fetched)} {
+			if !strings.Contains(body, `"acct":{"id":1,"name":"ann"}`) || strings.Contains(body, "hunter2") {
+				t.Errorf("failed response for %s: %s", query, body)
+				return
+			}
+		}
+	}
+}
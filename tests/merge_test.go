@@ -0,0 +1,73 @@
+package generator_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kennethklee/gin-gorm-rest/generator"
+	"gorm.io/gorm"
+)
+
+type Vet struct {
+	gorm.Model
+	Name    string `json:"name"`
+	License string `json:"license" rest:"readonly"`
+	Notes   string `json:"-"`
+}
+
+func TestUpdateModelDefaultMerger(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	req, _ := http.NewRequest("PUT", "/api/animals/1", strings.NewReader(`{"id": 9, "name": "changed", "species": "lion", "age": 4}`))
+	context, resp := mockContext(req)
+	context.Set("animal", &Animal{ID: 1, OwnerID: 1, Name: "Alfred", Species: "cat", Age: 2})
+
+	animalGenerator.Update(nil)(context)
+
+	if context.Writer.Status() != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s", context.Writer.Status(), string(body))
+		return
+	}
+
+	finalAnimal := Animal{}
+	animalGenerator.DB.Take(&finalAnimal, 1)
+	if finalAnimal.ID != 1 || finalAnimal.Name != "changed" || finalAnimal.Species != "lion" || finalAnimal.Age != 4 {
+		t.Errorf("incorrect db record: %+v", finalAnimal)
+		return
+	}
+}
+
+func TestUpdateModelDefaultMergerProtectedFields(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.DB.AutoMigrate(&Vet{})
+	vetGenerator := generator.New(animalGenerator.DB, Vet{}, "vet")
+
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	vet := &Vet{Model: gorm.Model{ID: 1, CreatedAt: created}, Name: "Doc", License: "L-1", Notes: "private"}
+	animalGenerator.DB.Create(vet)
+
+	req, _ := http.NewRequest("PUT", "/api/vets/1", strings.NewReader(`{"ID": 2, "CreatedAt": "2021-01-01T00:00:00Z", "name": "Who", "license": "L-2"}`))
+	context, resp := mockContext(req)
+	context.Set("vet", vet)
+
+	vetGenerator.Update(nil)(context)
+
+	if context.Writer.Status() != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s", context.Writer.Status(), string(body))
+		return
+	}
+
+	finalVet := Vet{}
+	animalGenerator.DB.Take(&finalVet, 1)
+	if finalVet.ID != 1 || !finalVet.CreatedAt.Equal(created) || finalVet.Name != "Who" || finalVet.License != "L-1" || finalVet.Notes != "private" {
+		t.Errorf("incorrect db record: %+v", finalVet)
+		return
+	}
+}
@@ -0,0 +1,168 @@
+package generator_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestListPaginatedModels(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Pagination = &generator.Pagination{}
+	defer func() { animalGenerator.Pagination = nil }()
+
+	req, _ := http.NewRequest("GET", "/animals?page=2&per_page=2", nil)
+	context, resp := mockContext(req)
+
+	animalGenerator.List(func(ctx *gin.Context, qs *gorm.DB) bool {
+		qs.Order("id asc")
+		return true
+	})(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusOK {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := []Animal{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+
+	if len(results) != 2 || results[0].Name != "Charlie" || results[1].Name != "Daisy" {
+		t.Errorf("failed response: %s", string(body))
+		return
+	}
+
+	if total := resp.Header().Get("X-Total-Count"); total != "6" {
+		t.Errorf("incorrect total count: %s", total)
+		return
+	}
+
+	link := resp.Header().Get("Link")
+	for _, expected := range []string{
+		`</animals?page=1&per_page=2>; rel="first"`,
+		`</animals?page=1&per_page=2>; rel="prev"`,
+		`</animals?page=3&per_page=2>; rel="next"`,
+		`</animals?page=3&per_page=2>; rel="last"`,
+	} {
+		if !strings.Contains(link, expected) {
+			t.Errorf("missing %s in link header: %s", expected, link)
+			return
+		}
+	}
+}
+
+func TestListAssociatedPaginatedEnvelope(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Pagination = &generator.Pagination{MaxSize: 2, Envelope: true}
+	defer func() { animalGenerator.Pagination = nil }()
+
+	req, _ := http.NewRequest("GET", "/owners/1/animals?offset=2&limit=10", nil)
+	context, resp := mockContext(req)
+	context.Set("owner", Owner{ID: 1})
+
+	animalGenerator.ListAssociated(ownerAnimalAssoc, nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusOK {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := struct {
+		Data   []Animal `json:"data"`
+		Total  int64    `json:"total"`
+		Offset int      `json:"offset"`
+		Limit  int      `json:"limit"`
+	}{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+
+	if results.Total != 3 || results.Offset != 2 || results.Limit != 2 || len(results.Data) != 1 || results.Data[0].Name != "Charlie" {
+		t.Errorf("failed response: %s", string(body))
+		return
+	}
+
+	if link := resp.Header().Get("Link"); strings.Contains(link, `rel="next"`) {
+		t.Errorf("should not link to next page: %s", link)
+		return
+	}
+}
+
+func TestListPaginationErrors(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Pagination = &generator.Pagination{}
+	defer func() { animalGenerator.Pagination = nil }()
+
+	req, _ := http.NewRequest("GET", "/animals?page=0&per_page=abc", nil)
+	context, resp := mockContext(req)
+
+	animalGenerator.List(nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := generator.ValidationErrorResponse{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+
+	if results.Errors["page"] != "min 1" || results.Errors["per_page"] != "invalid int type" {
+		t.Errorf("failed response: %s", string(body))
+		return
+	}
+}
+
+// Records the SQL statements of a session
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+func TestListPaginatedDefaultOrder(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	g := generator.New(animalGenerator.DB.Session(&gorm.Session{Logger: recorder}), Animal{}, "animal")
+	g.Pagination = &generator.Pagination{}
+
+	req, _ := http.NewRequest("GET", "/animals?offset=2&limit=2", nil)
+	context, resp := mockContext(req)
+
+	g.List(nil)(context)
+
+	if resp.Code != http.StatusOK || len(recorder.statements) == 0 {
+		t.Errorf("failed call with %d code", resp.Code)
+		return
+	}
+	if query := recorder.statements[len(recorder.statements)-1]; !strings.Contains(query, "ORDER BY `animals`.`id` LIMIT 2 OFFSET 2") {
+		t.Errorf("unordered page: %s", query)
+	}
+}
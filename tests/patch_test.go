@@ -0,0 +1,166 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kennethklee/gin-gorm-rest/generator"
+)
+
+func TestPatchModel(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	targetAnimal := Animal{}
+	animalGenerator.DB.Take(&targetAnimal, 1)
+
+	req, _ := http.NewRequest("PATCH", "/api/animals/1", strings.NewReader(`{"age": 0, "species": "lion"}`))
+	context, resp := mockContext(req)
+	context.Set("animal", &targetAnimal)
+
+	animalGenerator.Patch()(context)
+
+	if context.Writer.Status() != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s", context.Writer.Status(), string(body))
+		return
+	}
+
+	animal := context.MustGet("animal").(*Animal)
+	if animal.Name != "Alfred" || animal.Species != "lion" || animal.Age != 0 {
+		t.Errorf("incorrect response animal: %+v", animal)
+		return
+	}
+
+	// check database for change
+	finalAnimal := Animal{}
+	animalGenerator.DB.Take(&finalAnimal, 1)
+	if finalAnimal.Name != "Alfred" || finalAnimal.Species != "lion" || finalAnimal.Age != 0 {
+		t.Errorf("incorrect db record: %+v", finalAnimal)
+		return
+	}
+}
+
+func TestPatchModelErrors(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	req, _ := http.NewRequest("PATCH", "/api/animals/1", strings.NewReader(`{"id": 2, "age": "old", "color": "black"}`))
+	context, resp := mockContext(req)
+	context.Set("animal", &Animal{ID: 1, Name: "Alfred"})
+
+	animalGenerator.Patch()(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := generator.ValidationErrorResponse{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+	if results.Errors["id"] != "read only" || results.Errors["age"] != "invalid int type" || results.Errors["color"] != "unknown field" {
+		t.Errorf("failed response: %s", string(body))
+		return
+	}
+
+	finalAnimal := Animal{}
+	animalGenerator.DB.Take(&finalAnimal, 1)
+	if finalAnimal.Age != 2 {
+		t.Errorf("should not have changed db record: %+v", finalAnimal)
+		return
+	}
+}
+
+func TestJSONPatchModel(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	targetAnimal := Animal{}
+	animalGenerator.DB.Take(&targetAnimal, 1)
+
+	req, _ := http.NewRequest("PATCH", "/api/animals/1", strings.NewReader(`[
+		{"op": "test", "path": "/name", "value": "Alfred"},
+		{"op": "replace", "path": "/age", "value": 5},
+		{"op": "remove", "path": "/species"}
+	]`))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	context, resp := mockContext(req)
+	context.Set("animal", &targetAnimal)
+
+	animalGenerator.Patch()(context)
+
+	if context.Writer.Status() != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s", context.Writer.Status(), string(body))
+		return
+	}
+
+	finalAnimal := Animal{}
+	animalGenerator.DB.Take(&finalAnimal, 1)
+	if finalAnimal.Name != "Alfred" || finalAnimal.Species != "" || finalAnimal.Age != 5 {
+		t.Errorf("incorrect db record: %+v", finalAnimal)
+		return
+	}
+}
+
+func TestJSONPatchModelTestFailed(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	targetAnimal := Animal{}
+	animalGenerator.DB.Take(&targetAnimal, 1)
+
+	req, _ := http.NewRequest("PATCH", "/api/animals/1", strings.NewReader(`[
+		{"op": "replace", "path": "/age", "value": 5},
+		{"op": "test", "path": "/name", "value": "Bella"}
+	]`))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	context, resp := mockContext(req)
+	context.Set("animal", &targetAnimal)
+
+	animalGenerator.Patch()(context)
+
+	if resp.Code != http.StatusConflict {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	finalAnimal := Animal{}
+	animalGenerator.DB.Take(&finalAnimal, 1)
+	if finalAnimal.Age != 2 {
+		t.Errorf("should not have changed db record: %+v", finalAnimal)
+		return
+	}
+}
+
+func TestMergePatchModel(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	targetAnimal := Animal{}
+	animalGenerator.DB.Take(&targetAnimal, 1)
+
+	req, _ := http.NewRequest("PATCH", "/api/animals/1", strings.NewReader(`{"name": "Al", "age": null}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	context, resp := mockContext(req)
+	context.Set("animal", &targetAnimal)
+
+	animalGenerator.Patch()(context)
+
+	if context.Writer.Status() != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s", context.Writer.Status(), string(body))
+		return
+	}
+
+	finalAnimal := Animal{}
+	animalGenerator.DB.Take(&finalAnimal, 1)
+	if finalAnimal.Name != "Al" || finalAnimal.Species != "cat" || finalAnimal.Age != 0 {
+		t.Errorf("incorrect db record: %+v", finalAnimal)
+		return
+	}
+}
@@ -0,0 +1,243 @@
+package generator_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+)
+
+type Employee struct {
+	ID       uint   `json:"id" gorm:"primary_key"`
+	Name     string `json:"name"`
+	Salary   int    `json:"salary"`
+	Password string `json:"password" rest:"writeonly"`
+}
+
+// Only admins may see or set salaries
+func employeeGenerator() *generator.Generator {
+	animalGenerator.DB.AutoMigrate(&Employee{})
+	animalGenerator.DB.Create(&[]Employee{{ID: 1, Name: "Ann", Salary: 5000, Password: "secret"}, {ID: 2, Name: "Ben", Salary: 4000}})
+
+	g := generator.New(animalGenerator.DB, Employee{}, "employee")
+	g.FieldRules = map[string]generator.FieldRule{
+		"salary": {Read: generator.Roles("admin", "hr"), Write: generator.Roles("admin")},
+	}
+	return g
+}
+
+func TestFieldRulesRender(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g := employeeGenerator()
+
+	for role, expected := range map[string]string{"": `{"id":1,"name":"Ann"}`, "hr": `{"id":1,"name":"Ann","salary":5000}`} {
+		req, _ := http.NewRequest("GET", "/employees/1", nil)
+		context, resp := mockContext(req)
+		context.Set(generator.RolesKey, []string{role})
+		context.Params = gin.Params{gin.Param{Key: "employee", Value: "1"}}
+
+		g.Fetch()(context)
+		g.Render()(context)
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.Code != http.StatusOK || string(body) != expected {
+			t.Errorf("failed call for %s with %d code: %s", role, resp.Code, string(body))
+			return
+		}
+	}
+
+	// hidden fields can't be requested
+	req, _ := http.NewRequest("GET", "/employees/1?fields=id,salary", nil)
+	context, resp := mockContext(req)
+	context.Set("employee", &Employee{ID: 1})
+	g.Render()(context)
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("failed call with %d code", resp.Code)
+	}
+}
+
+func TestFieldRulesList(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g := employeeGenerator()
+
+	req, _ := http.NewRequest("GET", "/employees", nil)
+	context, resp := mockContext(req)
+
+	g.List(nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	var employees []map[string]interface{}
+	if err := json.Unmarshal(body, &employees); resp.Code != http.StatusOK || err != nil || len(employees) != 2 {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+	for _, employee := range employees {
+		if _, exists := employee["salary"]; exists {
+			t.Errorf("salary was not hidden: %s", string(body))
+			return
+		} else if _, exists := employee["password"]; exists {
+			t.Errorf("password was not hidden: %s", string(body))
+			return
+		}
+	}
+}
+
+func TestFieldRulesListQuery(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g := employeeGenerator()
+	g.Filters = []string{"*"}
+	g.Sorts = []string{"*"}
+
+	for _, test := range []struct {
+		query    string
+		role     string
+		expected int
+	}{
+		{"salary[gte]=4500", "", http.StatusBadRequest},
+		{"password=secret", "", http.StatusBadRequest},
+		{"sort=-salary", "", http.StatusBadRequest},
+		{"password=secret", "admin", http.StatusBadRequest},
+		{"salary[gte]=4500", "admin", http.StatusOK},
+		{"sort=-salary", "admin", http.StatusOK},
+	} {
+		req, _ := http.NewRequest("GET", "/employees?"+test.query, nil)
+		context, resp := mockContext(req)
+		context.Set(generator.RolesKey, []string{test.role})
+
+		g.List(nil)(context)
+
+		if resp.Code != test.expected {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed call for %s as %q with %d code: %s", test.query, test.role, resp.Code, string(body))
+			return
+		}
+	}
+
+	// cursors would reveal hidden keys
+	for _, key := range []string{"salary", "password"} {
+		g.Pagination = &generator.Pagination{Cursor: true, CursorKeys: []string{key, "id"}}
+		req, _ := http.NewRequest("GET", "/employees", nil)
+		context, resp := mockContext(req)
+		context.Set(generator.RolesKey, []string{"admin"})
+
+		g.List(nil)(context)
+
+		if resp.Code != http.StatusInternalServerError {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed call for %s cursor with %d code: %s", key, resp.Code, string(body))
+			return
+		}
+	}
+}
+
+func TestFieldRulesCreate(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g := employeeGenerator()
+
+	req, _ := http.NewRequest("POST", "/employees", strings.NewReader(`{"id": 3, "name": "Cat", "salary": 9000, "password": "hunter2"}`))
+	context, resp := mockContext(req)
+
+	g.Create()(context)
+
+	employee := Employee{}
+	if g.DB.Take(&employee, 3); context.Writer.Status() != http.StatusCreated || employee.Salary != 0 || employee.Password != "hunter2" {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s, %+v", context.Writer.Status(), string(body), employee)
+		return
+	}
+
+	g.RejectForbiddenFields = true
+	req, _ = http.NewRequest("POST", "/employees", strings.NewReader(`{"id": 4, "name": "Dan", "salary": 9000}`))
+	context, resp = mockContext(req)
+
+	g.Create()(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusUnprocessableEntity || string(body) != `{"message":"validation errors","errors":{"salary":"forbidden"}}` {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+}
+
+func TestFieldRulesUpdate(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g := employeeGenerator()
+
+	for _, test := range []struct {
+		method   string
+		handler  gin.HandlerFunc
+		body     string
+		reject   bool
+		expected int
+	}{
+		{"PUT", g.Update(nil), `{"name": "Anne"}`, true, http.StatusOK}, // omitted salary is kept
+		{"PUT", g.Update(nil), `{"name": "Anne", "salary": 9000}`, false, http.StatusOK},
+		{"PUT", g.Update(nil), `{"name": "Anne", "salary": 9000}`, true, http.StatusUnprocessableEntity},
+		{"PATCH", g.Patch(), `{"salary": 9000}`, false, http.StatusOK},
+		{"PATCH", g.Patch(), `{"salary": 9000}`, true, http.StatusUnprocessableEntity},
+	} {
+		g.RejectForbiddenFields = test.reject
+		employee := Employee{}
+		g.DB.Take(&employee, 1)
+
+		req, _ := http.NewRequest(test.method, "/employees/1", strings.NewReader(test.body))
+		context, resp := mockContext(req)
+		context.Set("employee", &employee)
+
+		test.handler(context)
+
+		final := Employee{}
+		if g.DB.Take(&final, 1); context.Writer.Status() != test.expected || final.Salary != 5000 || final.Password != "secret" {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed %s %s with %d code: %s, %+v", test.method, test.body, context.Writer.Status(), string(body), final)
+			return
+		}
+	}
+}
+
+func TestFieldRulesETag(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g := employeeGenerator()
+
+	fetch := func(role string) (string, string) {
+		req, _ := http.NewRequest("GET", "/employees/1", nil)
+		context, resp := mockContext(req)
+		context.Set(generator.RolesKey, []string{role})
+		context.Params = gin.Params{gin.Param{Key: "employee", Value: "1"}}
+
+		g.Fetch()(context)
+		g.Render()(context)
+
+		body, _ := io.ReadAll(resp.Body)
+		return resp.Header().Get("ETag"), string(body)
+	}
+
+	// the ETag hashes the rendered representation, so hidden salaries and passwords can't be guessed from it
+	etag, body := fetch("")
+	sum := sha256.Sum256([]byte(body))
+	if etag != `"`+hex.EncodeToString(sum[:16])+`"` {
+		t.Errorf("incorrect ETag %s of %s", etag, body)
+		return
+	}
+	g.DB.Model(&Employee{ID: 1}).Updates(map[string]interface{}{"salary": 5123, "password": "guessable"})
+	if changed, _ := fetch(""); changed != etag {
+		t.Errorf("ETag changed with hidden fields: %s, %s", etag, changed)
+		return
+	}
+	if admin, _ := fetch("hr"); admin == etag {
+		t.Errorf("same ETag for readers of the salary: %s", admin)
+		return
+	}
+}
@@ -0,0 +1,169 @@
+package generator_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lets owners manage their own animals, given by the X-Owner header
+type ownerPolicy struct{}
+
+func (ownerPolicy) CanList(c *gin.Context) bool {
+	return c.GetHeader("X-Owner") != ""
+}
+
+func (ownerPolicy) CanRead(c *gin.Context, model interface{}) bool {
+	return ownsAnimal(c, model)
+}
+
+func (ownerPolicy) CanCreate(c *gin.Context, model interface{}) bool {
+	return ownsAnimal(c, model)
+}
+
+func (ownerPolicy) CanUpdate(c *gin.Context, old interface{}, model interface{}) bool {
+	return ownsAnimal(c, old) && ownsAnimal(c, model)
+}
+
+func (ownerPolicy) CanDelete(c *gin.Context, model interface{}) bool {
+	return ownsAnimal(c, model)
+}
+
+func ownsAnimal(c *gin.Context, model interface{}) bool {
+	animal := model.(*Animal)
+	return c.GetHeader("X-Owner") == "1" && animal.OwnerID == 1
+}
+
+func TestPolicyList(t *testing.T) {
+	animalGenerator.Policy = ownerPolicy{}
+	defer func() { animalGenerator.Policy = nil }()
+
+	for owner, expected := range map[string]int{"": http.StatusForbidden, "1": http.StatusOK} {
+		req, _ := http.NewRequest("GET", "/animals", nil)
+		req.Header.Set("X-Owner", owner)
+		context, resp := mockContext(req)
+
+		animalGenerator.List(nil)(context)
+
+		if resp.Code != expected {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed call for %s with %d code: %s", owner, resp.Code, string(body))
+			return
+		}
+	}
+}
+
+func TestPolicyFetch(t *testing.T) {
+	animalGenerator.Policy = ownerPolicy{}
+	defer func() { animalGenerator.Policy, animalGenerator.HideForbidden = nil, false }()
+
+	for _, hide := range []bool{false, true} {
+		animalGenerator.HideForbidden = hide
+		req, _ := http.NewRequest("GET", "/animals/1", nil)
+		req.Header.Set("X-Owner", "2")
+		context, resp := mockContext(req)
+		context.Params = gin.Params{gin.Param{Key: "animal", Value: "1"}}
+
+		animalGenerator.Fetch()(context)
+
+		body, _ := io.ReadAll(resp.Body)
+		if hide && (resp.Code != http.StatusNotFound || string(body) != `{"message":"not found"}`) {
+			t.Errorf("failed hidden call with %d code: %s", resp.Code, string(body))
+			return
+		} else if !hide && (resp.Code != http.StatusForbidden || string(body) != `{"message":"forbidden"}`) {
+			t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+			return
+		}
+	}
+}
+
+func TestPolicyCreateAndUpdate(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Policy = ownerPolicy{}
+	defer func() { animalGenerator.Policy = nil }()
+
+	// creating an animal for another owner
+	req, _ := http.NewRequest("POST", "/animals", strings.NewReader(`{"id": 99, "owner_id": 2, "name": "Puff"}`))
+	req.Header.Set("X-Owner", "1")
+	context, resp := mockContext(req)
+	animalGenerator.Create()(context)
+	if resp.Code != http.StatusForbidden {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed create with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	// giving an animal away
+	for method, handler := range map[string]gin.HandlerFunc{"PUT": animalGenerator.Update(nil), "PATCH": animalGenerator.Patch()} {
+		animal := Animal{}
+		animalGenerator.DB.Take(&animal, 1)
+		req, _ = http.NewRequest(method, "/animals/1", strings.NewReader(`{"owner_id": 2}`))
+		req.Header.Set("X-Owner", "1")
+		context, resp = mockContext(req)
+		context.Set("animal", &animal)
+
+		handler(context)
+
+		final := Animal{}
+		if animalGenerator.DB.Take(&final, 1); resp.Code != http.StatusForbidden || final.OwnerID != 1 {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed %s with %d code: %s", method, resp.Code, string(body))
+			return
+		}
+	}
+}
+
+func TestPolicyDelete(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Policy = ownerPolicy{}
+	defer func() { animalGenerator.Policy = nil }()
+
+	req, _ := http.NewRequest("DELETE", "/animals/1", nil)
+	req.Header.Set("X-Owner", "2")
+	context, resp := mockContext(req)
+	context.Set("animal", &Animal{ID: 1, OwnerID: 1})
+
+	animalGenerator.Delete()(context)
+
+	var count int64
+	if animalGenerator.DB.Model(&Animal{}).Where("id = ?", 1).Count(&count); resp.Code != http.StatusForbidden || count != 1 {
+		t.Errorf("failed call with %d code and %d records", resp.Code, count)
+		return
+	}
+}
+
+// Hides dogs, whatever the requested fields
+type noDogsPolicy struct{ ownerPolicy }
+
+func (noDogsPolicy) CanRead(c *gin.Context, model interface{}) bool {
+	return model.(*Animal).Species != "dog"
+}
+
+func TestPolicyFetchFields(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Policy = noDogsPolicy{}
+	defer func() { animalGenerator.Policy = nil }()
+
+	for id, expected := range map[string]string{"1": `{"id":1,"name":"Alfred"}`, "2": `{"message":"forbidden"}`} {
+		req, _ := http.NewRequest("GET", "/animals/"+id+"?fields=id,name", nil)
+		context, resp := mockContext(req)
+		context.Params = gin.Params{gin.Param{Key: "animal", Value: id}}
+
+		animalGenerator.Fetch()(context)
+		if !context.IsAborted() {
+			animalGenerator.Render()(context)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != expected {
+			t.Errorf("failed call for %s with %d code: %s", id, resp.Code, string(body))
+			return
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package generator_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+	"gorm.io/gorm"
+)
+
+func TestDBResolver(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	// a generator without a DB of its own, queries go to the database of the request
+	g := generator.New(nil, Animal{}, "animal")
+	g.DBResolver = func(c *gin.Context) *gorm.DB {
+		return c.MustGet("db").(*gorm.DB).Where("species = ?", "cat")
+	}
+	g.Sorts = []string{"age"}
+
+	req, _ := http.NewRequest("GET", "/animals?sort=-age&fields=name,species,age", nil)
+	context, resp := mockContext(req)
+	context.Set("db", animalGenerator.DB)
+
+	g.List(nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	animals := []Animal{}
+	if err := json.Unmarshal(body, &animals); resp.Code != http.StatusOK || err != nil {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+	for i, animal := range animals {
+		if animal.Species != "cat" || animal.ID != 0 || (i > 0 && animal.Age > animals[i-1].Age) {
+			t.Errorf("incorrect animals: %+v", animals)
+			return
+		}
+	}
+}
+
+func TestDBRequestContext(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/animals", nil)
+	context, resp := mockContext(req)
+
+	animalGenerator.List(nil)(context)
+
+	if resp.Code != http.StatusInternalServerError {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("cancelled call with %d code: %s", resp.Code, string(body))
+		return
+	}
+}
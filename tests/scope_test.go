@@ -0,0 +1,138 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+)
+
+type Invoice struct {
+	ID       uint   `json:"id" gorm:"primary_key"`
+	TenantID uint   `json:"tenant_id"`
+	Number   string `json:"number"`
+}
+
+// Scopes invoices to the tenant of the X-Tenant header
+func tenantGenerator() *generator.Generator {
+	animalGenerator.DB.AutoMigrate(&Invoice{})
+	animalGenerator.DB.Create(&[]Invoice{{ID: 1, TenantID: 1, Number: "A-1"}, {ID: 2, TenantID: 2, Number: "B-1"}, {ID: 3, TenantID: 1, Number: "A-2"}})
+
+	g := generator.New(animalGenerator.DB, Invoice{}, "invoice")
+	g.Scope = func(c *gin.Context) (map[string]interface{}, error) {
+		if c.GetHeader("X-Tenant") == "" {
+			return nil, &generator.Error{Status: http.StatusUnauthorized, Message: "unauthorized"}
+		}
+		return map[string]interface{}{"tenant_id": c.GetHeader("X-Tenant")}, nil
+	}
+	return g
+}
+
+func TestScopeList(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g := tenantGenerator()
+
+	req, _ := http.NewRequest("GET", "/invoices", nil)
+	req.Header.Set("X-Tenant", "1")
+	context, resp := mockContext(req)
+
+	g.List(nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	invoices := []Invoice{}
+	if err := json.Unmarshal(body, &invoices); resp.Code != http.StatusOK || err != nil {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+	if len(invoices) != 2 || invoices[0].Number != "A-1" || invoices[1].Number != "A-2" {
+		t.Errorf("incorrect invoices: %+v", invoices)
+		return
+	}
+
+	// no principal
+	req, _ = http.NewRequest("GET", "/invoices", nil)
+	context, resp = mockContext(req)
+	g.List(nil)(context)
+	if resp.Code != http.StatusUnauthorized {
+		t.Errorf("unscoped call with %d code", resp.Code)
+	}
+}
+
+func TestScopeFetch(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g := tenantGenerator()
+
+	for id, expected := range map[string]int{"1": http.StatusOK, "2": http.StatusNotFound} {
+		req, _ := http.NewRequest("GET", "/invoices/"+id, nil)
+		req.Header.Set("X-Tenant", "1")
+		context, resp := mockContext(req)
+		context.Params = gin.Params{gin.Param{Key: "invoice", Value: id}}
+
+		g.Fetch()(context)
+
+		if resp.Code != expected {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed call for %s with %d code: %s", id, resp.Code, string(body))
+			return
+		}
+	}
+}
+
+func TestScopeCreateAndUpdate(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g := tenantGenerator()
+
+	req, _ := http.NewRequest("POST", "/invoices", strings.NewReader(`{"id": 4, "tenant_id": 2, "number": "A-3"}`))
+	req.Header.Set("X-Tenant", "1")
+	context, resp := mockContext(req)
+
+	g.Create()(context)
+
+	invoice := Invoice{}
+	if g.DB.Take(&invoice, 4); context.Writer.Status() != http.StatusCreated || invoice.TenantID != 1 {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s, %+v", context.Writer.Status(), string(body), invoice)
+		return
+	}
+
+	// moving a record to another tenant is not possible
+	req, _ = http.NewRequest("PUT", "/invoices/4", strings.NewReader(`{"tenant_id": 2, "number": "A-4"}`))
+	req.Header.Set("X-Tenant", "1")
+	context, resp = mockContext(req)
+	context.Set("invoice", &invoice)
+
+	g.Update(nil)(context)
+
+	invoice = Invoice{}
+	if g.DB.Take(&invoice, 4); context.Writer.Status() != http.StatusOK || invoice.TenantID != 1 || invoice.Number != "A-4" {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s, %+v", context.Writer.Status(), string(body), invoice)
+		return
+	}
+}
+
+func TestScopeDelete(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g := tenantGenerator()
+
+	req, _ := http.NewRequest("DELETE", "/invoices/2", nil)
+	req.Header.Set("X-Tenant", "1")
+	context, resp := mockContext(req)
+	context.Set("invoice", &Invoice{ID: 2})
+
+	g.Delete()(context)
+
+	var count int64
+	if g.DB.Model(&Invoice{}).Where("id = ?", 2).Count(&count); resp.Code != http.StatusNotFound || count != 1 {
+		t.Errorf("failed call with %d code and %d records", resp.Code, count)
+		return
+	}
+}
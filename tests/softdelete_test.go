@@ -0,0 +1,114 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+	"gorm.io/gorm"
+)
+
+// Registers the vet routes with a trashed vet
+func vetRouter() (*generator.Generator, *gin.Engine) {
+	animalGenerator.DB.AutoMigrate(&Vet{})
+	animalGenerator.DB.Create(&[]Vet{{Model: gorm.Model{ID: 1}, Name: "Ann"}, {Model: gorm.Model{ID: 2}, Name: "Ben"}})
+	animalGenerator.DB.Delete(&Vet{}, 2)
+
+	g := generator.New(animalGenerator.DB, Vet{}, "vet")
+	g.ForceDelete = generator.Roles("admin")
+	router := gin.New()
+	g.Handlers(nil, nil).Register(router, "/vets", func(c *gin.Context) {
+		c.Set(generator.RolesKey, c.GetHeader("X-Role"))
+	})
+	return g, router
+}
+
+func TestListTrashed(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	_, router := vetRouter()
+
+	for query, expected := range map[string]string{"": "Ann", "?trashed=with": "Ann,Ben", "?trashed=only": "Ben"} {
+		resp := serve(router, "GET", "/vets"+query, "")
+
+		body, _ := io.ReadAll(resp.Body)
+		vets := []Vet{}
+		if err := json.Unmarshal(body, &vets); resp.Code != http.StatusOK || err != nil {
+			t.Errorf("failed call for %s with %d code: %s", query, resp.Code, string(body))
+			return
+		}
+		names := ""
+		for i, vet := range vets {
+			if i > 0 {
+				names += ","
+			}
+			names += vet.Name
+		}
+		if names != expected {
+			t.Errorf("incorrect vets for %s: %s", query, names)
+			return
+		}
+	}
+
+	if resp := serve(router, "GET", "/vets?trashed=all", ""); resp.Code != http.StatusBadRequest {
+		t.Errorf("failed invalid call with %d code", resp.Code)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g, router := vetRouter()
+
+	if resp := serve(router, "POST", "/vets/1/restore", ""); resp.Code != http.StatusNotFound {
+		t.Errorf("restored a vet that wasn't deleted with %d code", resp.Code)
+		return
+	}
+
+	resp := serve(router, "POST", "/vets/2/restore", "")
+	body, _ := io.ReadAll(resp.Body)
+	vet := Vet{}
+	if err := g.DB.Take(&vet, 2).Error; resp.Code != http.StatusOK || err != nil {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	// models that are deleted for good have no restore route
+	router = gin.New()
+	animalGenerator.Handlers(nil, nil).Register(router, "/animals")
+	if resp := serve(router, "POST", "/animals/1/restore", ""); resp.Code != http.StatusNotFound {
+		t.Errorf("restore route registered with %d code", resp.Code)
+	}
+}
+
+func TestForceDelete(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g, router := vetRouter()
+
+	if resp := serve(router, "DELETE", "/vets/1?force=true", ""); resp.Code != http.StatusForbidden {
+		t.Errorf("failed call with %d code", resp.Code)
+		return
+	}
+
+	// soft delete, then purge from the trash
+	for _, role := range []string{"", "admin"} {
+		path := "/vets/1"
+		if role != "" {
+			path += "?force=true"
+		}
+		if resp := serve(router, "DELETE", path, "", "X-Role", role); resp.Code != http.StatusNoContent {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed call for %s with %d code: %s", path, resp.Code, string(body))
+			return
+		}
+	}
+
+	var count int64
+	if g.DB.Unscoped().Model(&Vet{}).Where("id = ?", 1).Count(&count); count != 0 {
+		t.Errorf("vet was not purged")
+	}
+}
@@ -0,0 +1,101 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/kennethklee/gin-gorm-rest/generator"
+)
+
+func TestListSortedModels(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	animalGenerator.Sorts = []string{"age", "name"}
+	defer func() { animalGenerator.Sorts = nil }()
+
+	req, _ := http.NewRequest("GET", "/animals?sort=-age,name", nil)
+	context, resp := mockContext(req)
+
+	animalGenerator.List(nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusOK {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := []Animal{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+
+	expected := []string{"Charlie", "Fred", "Alfred", "Daisy", "Bella", "Ella"}
+	if len(results) != len(expected) {
+		t.Errorf("failed count: %s", string(body))
+		return
+	}
+	for i, name := range expected {
+		if results[i].Name != name {
+			t.Errorf("failed response at %d: %s", i, string(body))
+			return
+		}
+	}
+}
+
+func TestListAssociatedSortedByPrimaryKey(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	req, _ := http.NewRequest("GET", "/owners/1/animals?sort=-id", nil)
+	context, resp := mockContext(req)
+	context.Set("owner", Owner{ID: 1})
+
+	animalGenerator.ListAssociated(ownerAnimalAssoc, nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusOK {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := []Animal{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+
+	if len(results) != 3 || results[0].Name != "Charlie" || results[2].Name != "Alfred" {
+		t.Errorf("failed response: %s", string(body))
+		return
+	}
+}
+
+func TestListUnsortableField(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	req, _ := http.NewRequest("GET", "/animals?sort=id,name", nil)
+	context, resp := mockContext(req)
+
+	animalGenerator.List(nil)(context)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	results := generator.ValidationErrorResponse{}
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Errorf("failed JSON response decode: %v\nfull body: %s", err, string(body))
+		return
+	}
+
+	if results.Errors["sort"] != "unsortable field name" {
+		t.Errorf("failed response: %s", string(body))
+		return
+	}
+}
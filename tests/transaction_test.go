@@ -0,0 +1,74 @@
+package generator_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+	"gorm.io/gorm"
+)
+
+func TestTransactionMiddleware(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	router := gin.New()
+	router.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err interface{}) { c.AbortWithStatus(http.StatusInternalServerError) }))
+	router.Use(generator.Transaction(animalGenerator.DB))
+	router.POST("/animals", animalGenerator.Create(), animalGenerator.Render())
+	router.POST("/aborted", animalGenerator.Create(), func(c *gin.Context) { c.AbortWithStatus(http.StatusBadRequest) })
+	router.POST("/panicked", animalGenerator.Create(), func(c *gin.Context) { panic("oops") })
+
+	// committed last, the others would conflict with it
+	for _, test := range []struct {
+		path     string
+		expected int
+	}{{"/aborted", http.StatusBadRequest}, {"/panicked", http.StatusInternalServerError}, {"/animals", http.StatusCreated}} {
+		path, expected := test.path, test.expected
+		req, _ := http.NewRequest("POST", path, strings.NewReader(`{"id": 99, "name": "Puff", "species": "cat", "age": 1}`))
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		if resp.Code != expected {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed call for %s with %d code: %s", path, resp.Code, string(body))
+			return
+		}
+		var count int64
+		animalGenerator.DB.Model(&Animal{}).Where("id = ?", 99).Count(&count)
+		if committed := count == 1; committed != (expected == http.StatusCreated) {
+			t.Errorf("incorrect commit for %s: %d records", path, count)
+			return
+		}
+	}
+}
+
+func TestTransactionCommitFailure(t *testing.T) {
+	router := gin.New()
+	// the test transaction would nest it in a savepoint, which has no commit of its own
+	router.Use(generator.Transaction(origDB))
+	// ending the transaction early makes the commit of the middleware fail
+	router.POST("/animals", animalGenerator.Create(), animalGenerator.Render(), func(c *gin.Context) {
+		c.MustGet(generator.TxKey).(*gorm.DB).Rollback()
+	})
+
+	req, _ := http.NewRequest("POST", "/animals", strings.NewReader(`{"id": 99, "name": "Puff", "species": "cat", "age": 1}`))
+	resp := httptest.NewRecorder()
+
+	router.ServeHTTP(resp, req)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusInternalServerError || strings.Contains(string(body), "Puff") {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+	var count int64
+	if origDB.Model(&Animal{}).Where("id = ?", 99).Count(&count); count != 0 {
+		t.Errorf("create was not rolled back")
+	}
+}
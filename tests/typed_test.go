@@ -0,0 +1,72 @@
+package generator_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+)
+
+func TestTypedFetchModel(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	typedGenerator := generator.NewOf[Animal](animalGenerator.DB, "animal")
+
+	req, _ := http.NewRequest("GET", "", nil)
+	context, resp := mockContext(req)
+	context.Params = gin.Params{gin.Param{Key: "animal", Value: "1"}}
+
+	typedGenerator.Fetch()(context)
+
+	if resp.Code != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+
+	animal, ok := typedGenerator.Get(context)
+	if !ok {
+		t.Errorf("should have found animal in context")
+		return
+	}
+	if animal.Name != "Alfred" {
+		t.Errorf("incorrect name: %s", animal.Name)
+		return
+	}
+}
+
+func TestTypedUpdateModel(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	typedGenerator := generator.NewOf[Animal](animalGenerator.DB, "animal")
+
+	req, _ := http.NewRequest("PUT", "/api/animals/1", strings.NewReader(`{"name": "changed"}`))
+	context, resp := mockContext(req)
+	context.Set("animal", &Animal{ID: 1})
+
+	typedGenerator.Update(func(src, dest *Animal) error {
+		dest.Name = src.Name
+		return nil
+	})(context)
+
+	if context.Writer.Status() != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s", context.Writer.Status(), string(body))
+		return
+	}
+
+	if animal := typedGenerator.MustGet(context); animal.Name != "changed" {
+		t.Errorf("incorrect response name: %s", animal.Name)
+		return
+	}
+
+	finalAnimal := Animal{}
+	animalGenerator.DB.Take(&finalAnimal, 1)
+	if finalAnimal.Name != "changed" {
+		t.Errorf("incorrect db record name: %s", finalAnimal.Name)
+		return
+	}
+}
@@ -0,0 +1,118 @@
+package generator_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+	"gorm.io/gorm"
+)
+
+func TestUpsert(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	g := generator.New(animalGenerator.DB, Animal{}, "animal")
+	g.Upsert = true
+	router := gin.New()
+	g.Handlers(nil, nil).Register(router, "/animals")
+
+	for _, test := range []struct {
+		body     string
+		expected int
+		name     string
+	}{
+		{`{"name": "Rex", "species": "dog"}`, http.StatusCreated, "Rex"},
+		{`{"name": "Max", "species": "dog"}`, http.StatusOK, "Max"},
+	} {
+		resp := serve(router, "PUT", "/animals/90", test.body)
+		animal := Animal{}
+		if g.DB.Take(&animal, 90); resp.Code != test.expected || animal.Name != test.name {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed call for %s with %d code: %s", test.body, resp.Code, string(body))
+			return
+		}
+	}
+
+	if resp := serve(router, "PUT", "/animals/91", `{"id": 92, "name": "Rex"}`); resp.Code != http.StatusBadRequest {
+		t.Errorf("failed call with another key with %d code", resp.Code)
+		return
+	}
+
+	// records are created only when enabled
+	router = gin.New()
+	animalGenerator.Handlers(nil, nil).Register(router, "/animals")
+	if resp := serve(router, "PUT", "/animals/93", `{"name": "Rex"}`); resp.Code != http.StatusNotFound {
+		t.Errorf("created without upsert with %d code", resp.Code)
+	}
+}
+
+func TestUpsertOutOfScope(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	g := generator.New(animalGenerator.DB, Animal{}, "animal")
+	g.Upsert = true
+	g.Scope = func(c *gin.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"owner_id": 2}, nil
+	}
+	router := gin.New()
+	g.Handlers(nil, nil).Register(router, "/animals")
+
+	resp := serve(router, "PUT", "/animals/1", `{"name": "Rex"}`)
+	animal := Animal{}
+	if g.DB.Take(&animal, 1); resp.Code != http.StatusConflict || animal.Name != "Alfred" || animal.OwnerID != 1 {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("failed call with %d code: %s, %+v", resp.Code, string(body), animal)
+	}
+}
+
+func TestUpsertConcurrentlyCreated(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+
+	for owner, expected := range map[uint]int{1: http.StatusCreated, 2: http.StatusConflict} {
+		g := generator.New(animalGenerator.DB, Animal{}, "animal")
+		g.Upsert = true
+		g.Scope = func(c *gin.Context) (map[string]interface{}, error) {
+			return map[string]interface{}{"owner_id": 1}, nil
+		}
+		// another request creates the record after it was found missing
+		id := 90 + owner
+		g.BeforeCreate = func(c *gin.Context, tx *gorm.DB, model interface{}) error {
+			return tx.Create(&Animal{ID: id, OwnerID: owner, Name: "Racer"}).Error
+		}
+		router := gin.New()
+		g.Handlers(nil, nil).Register(router, "/animals")
+
+		resp := serve(router, "PUT", fmt.Sprintf("/animals/%d", id), `{"name": "Rex"}`)
+		animal := Animal{}
+		g.DB.Take(&animal, id)
+		replaced := animal.Name == "Rex" && animal.OwnerID == 1
+		if resp.Code != expected || replaced != (expected == http.StatusCreated) {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("failed call for owner %d with %d code: %s, %+v", owner, resp.Code, string(body), animal)
+			return
+		}
+	}
+}
+
+func TestUpsertSoftDeleted(t *testing.T) {
+	testSetup()
+	defer testTearDown()
+	g, router := vetRouter()
+	g.Upsert = true
+
+	resp := serve(router, "PUT", "/vets/2", `{"name": "Cat"}`)
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Code != http.StatusConflict || string(body) != `{"message":"record is deleted, restore it first"}` {
+		t.Errorf("failed call with %d code: %s", resp.Code, string(body))
+		return
+	}
+	if resp := serve(router, "PUT", "/vets/3", `{"name": "Cat"}`); resp.Code != http.StatusCreated {
+		t.Errorf("failed create with %d code", resp.Code)
+	}
+}
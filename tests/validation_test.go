@@ -0,0 +1,74 @@
+package generator_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/validator/v10"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	"github.com/kennethklee/gin-gorm-rest/generator"
+)
+
+type Kennel struct {
+	ID   uint   `json:"id" gorm:"primary_key"`
+	Name string `json:"name" binding:"required"`
+
+	Pets []Pet `json:"pets" gorm:"-" binding:"dive"`
+}
+type Pet struct {
+	Name string `json:"pet_name" binding:"required,min=2"`
+}
+
+func TestValidationErrors(t *testing.T) {
+	g := generator.New(animalGenerator.DB, Kennel{}, "kennel")
+	if err := generator.RegisterTranslation(fr.New(), fr_translations.RegisterDefaultTranslations); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		language string
+		expected map[string]generator.FieldError
+	}{
+		{"", map[string]generator.FieldError{
+			"name":             {Tag: "required", Message: "name is a required field"},
+			"pets[1].pet_name": {Tag: "min", Param: "2", Message: "pet_name must be at least 2 characters in length"},
+		}},
+		{"de-DE, fr-CA;q=0.8, en;q=0.5", map[string]generator.FieldError{
+			"name":             {Tag: "required", Message: "name est un champ obligatoire"},
+			"pets[1].pet_name": {Tag: "min", Param: "2", Message: "pet_name doit faire une taille minimum de 2 caractères"},
+		}},
+	} {
+		req, _ := http.NewRequest("POST", "/kennels", strings.NewReader(`{"pets": [{"pet_name": "Rex"}, {"pet_name": "Q"}]}`))
+		req.Header.Set("Accept-Language", test.language)
+		context, resp := mockContext(req)
+
+		g.Create()(context)
+
+		body, _ := io.ReadAll(resp.Body)
+		result := generator.ValidationErrorResponse{}
+		if err := json.Unmarshal(body, &result); err != nil || resp.Code != http.StatusBadRequest || len(result.Fields) != len(test.expected) {
+			t.Errorf("failed call for %q with %d code: %s", test.language, resp.Code, string(body))
+			return
+		}
+		for path, fieldErr := range test.expected {
+			if result.Fields[path] != fieldErr || result.Errors[path] != fieldErr.Tag {
+				t.Errorf("incorrect %s error for %q: %s", path, test.language, string(body))
+			}
+		}
+	}
+}
+
+func TestValidationLeavesGinValidator(t *testing.T) {
+	generator.New(animalGenerator.DB, Kennel{}, "kennel")
+
+	// gin's validator still names fields by their struct names
+	err := binding.Validator.ValidateStruct(&Kennel{})
+	if ve, ok := err.(validator.ValidationErrors); !ok || len(ve) != 1 || ve[0].Field() != "Name" {
+		t.Errorf("incorrect gin validation: %v", err)
+	}
+}